@@ -0,0 +1,157 @@
+package main
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"strconv"
+	"time"
+)
+
+// ------------------- Autoplay -------------------------------
+
+// handleAutoKey takes the autoplay keys: a toggles it, p pauses, + and - change the pace,
+// s and b cycle the strategy and the bet policy
+func handleAutoKey(m blackjackModel, key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "a":
+		if m.Auto.On {
+			m.Auto.On = false
+			m.Auto.Generation++ // drops the pending tick
+			return m, nil
+		}
+		m = startAutoplay(m)
+		return m, m.autoTick()
+	case "p":
+		if !m.Auto.On {
+			return m, nil
+		}
+		m.Auto.Paused = !m.Auto.Paused
+		m.Auto.Generation++
+		if m.Auto.Paused {
+			return m, nil
+		}
+		return m, m.autoTick()
+	case "+":
+		m.Auto.Pace = max(m.Auto.Pace/2, autoMinPace)
+	case "-":
+		m.Auto.Pace = min(m.Auto.Pace*2, autoMaxPace)
+	case "s":
+		names := strategyNames()
+		m.Auto.Strategy = names[(indexOf(names, m.Auto.Strategy)+1)%len(names)]
+	case "b":
+		names := betPolicyNames()
+		m.Auto.Policy = names[(indexOf(names, m.Auto.Policy)+1)%len(names)]
+		m.Auto.Bettor.Policy = betPolicies[m.Auto.Policy]
+		m.Auto.Bettor.restart()
+	}
+	return m, nil
+}
+
+func startAutoplay(m blackjackModel) blackjackModel {
+	if m.Auto.Pace == 0 {
+		m.Auto.Pace = autoDefaultPace
+		m.Auto.Strategy = "basic"
+		m.Auto.Policy = "flat"
+	}
+	m.Auto.On = true
+	m.Auto.Paused = false
+	m.Auto.Generation++
+	m.Auto.Bettor = newBettor(betPolicies[m.Auto.Policy], 10, 10, 50, 5, 0.5,
+		baselineEdge(m.Game.Payout, m.Game.HitOnSoft17, m.Game.NumberDecks))
+	m.Auto.Bettor.Seed = m.Game.RandomSeed
+	m.Auto.Bettor.CardsDealt = m.Game.CardsDealt
+	if m.Game.Phase == phasePlay {
+		// record counts the hand on the table once it is over
+		m.Auto.Bettor.Running = countBeforeHand(m.Game)
+		m.Auto.MoneyBefore = m.Game.PlayerMoney + m.Game.Bet
+		m.Auto.Recorded = false
+	} else {
+		m.Auto.Bettor.Running = m.Game.HiLoCount
+		m.Auto.Recorded = true
+	}
+	return m
+}
+
+// autoTick schedules the next synthetic key the same way windowSize schedules customHideCursorMsg
+func (m blackjackModel) autoTick() tea.Cmd {
+	generation := m.Auto.Generation
+	return tea.Tick(m.Auto.Pace, func(t time.Time) tea.Msg { return customAutoTickMsg{generation: generation} })
+}
+
+// handleAutoTick sends one key per tick through the program, like a player would press
+// it, so the whole state machine of Update is exercised and stays watchable
+func handleAutoTick(m blackjackModel, msg customAutoTickMsg) (tea.Model, tea.Cmd) {
+	if !m.Auto.On || m.Auto.Paused || msg.generation != m.Auto.Generation {
+		return m, nil
+	}
+	m, key, ok := autoKey(m)
+	if !ok {
+		return m, m.autoTick()
+	}
+	return m, tea.Batch(func() tea.Msg { return key }, m.autoTick())
+}
+
+// autoKey moves the cursor to the option the bot wants and presses enter once it is there
+func autoKey(m blackjackModel) (blackjackModel, tea.KeyMsg, bool) {
+	if m.Game.Phase == phaseQuiz {
+		return m, autoQuizKey(m), true
+	}
+
+	target := -1
+	switch m.Game.Phase {
+	case phaseBet:
+		m.Auto.MoneyBefore = m.Game.PlayerMoney
+		m.Auto.Recorded = false // a natural skips phasePlay
+		bet := m.Auto.Bettor.next(m.Game, int(m.Game.PlayerMoney))
+		target = bet/10 - 1
+	case phasePlay:
+		action := strategies[m.Auto.Strategy](countedView(m.Game))
+		if !legalAction(m.Game, action) {
+			action = actionHit // like simRound
+		}
+		target = indexOf(currentOptions(m), actionOption(m, action))
+	case phaseEnd:
+		if !m.Auto.Recorded {
+			m.Auto.Recorded = true
+			m.Auto.Rounds++
+			net := int(m.Game.PlayerMoney) - int(m.Auto.MoneyBefore)
+			m.Auto.Bettor.record(m.Game, int(m.Game.Bet), net)
+		}
+		target = indexOf(currentOptions(m), m.UiText.OptionRestart)
+	}
+
+	switch {
+	case target < 0:
+		return m, tea.KeyMsg{}, false
+	case m.UiState.Cursor > target:
+		return m, tea.KeyMsg{Type: tea.KeyUp}, true
+	case m.UiState.Cursor < target:
+		return m, tea.KeyMsg{Type: tea.KeyDown}, true
+	default:
+		return m, tea.KeyMsg{Type: tea.KeyEnter}, true
+	}
+}
+
+// autoQuizKey types the exact count, then confirms the question and the result
+func autoQuizKey(m blackjackModel) tea.KeyMsg {
+	if m.Trainer.Answered || m.Trainer.Input != emptyString {
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	}
+	running := m.Trainer.system().runningCount(dealtCards(m.Game))
+	answer := strconv.Itoa(running)
+	if m.Trainer.AskTrueCount {
+		answer = strconv.FormatFloat(trueCount(running, m.Game), 'f', 1, 64)
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(answer)}
+}
+
+func autoLabel(m blackjackModel) string {
+	if !m.Auto.On {
+		return emptyString
+	}
+	label := m.UiText.AutoLabel + m.Auto.Strategy + ", " + m.Auto.Policy + ", " +
+		m.Auto.Pace.String() + ", " + strconv.Itoa(m.Auto.Rounds) + m.UiText.AutoRounds
+	if m.Auto.Paused {
+		label += m.UiText.AutoPaused
+	}
+	return label
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"testing"
+)
+
+func TestAutoplayPlaysThroughUpdate(t *testing.T) {
+	resetStorage(t)
+	gs := gameState{Phase: phaseBet, NumberDecks: 2, Payout: 15, PlayerMoney: 2000, ReshuffleThreshold: reshuffleThreshold(2, 50)}
+	gs.DrawStack, gs.RandomSeed = newDeck(gs.NumberDecks, make([]card, 0, 104), 21)
+	m := tableModel(t, gs)
+	m.Auto.Pace = autoDefaultPace
+	m.Auto.Strategy = "basic"
+	m.Auto.Policy = "ramp"
+	m = startAutoplay(m)
+
+	for step := 0; m.Auto.Rounds < 300; step++ {
+		if step > 100000 {
+			t.Fatalf("%d rounds after %d keys, stuck in phase %v", m.Auto.Rounds, step, m.Game.Phase)
+		}
+		var key tea.KeyMsg
+		var ok bool
+		m, key, ok = autoKey(m)
+		if !ok {
+			t.Fatalf("no key in phase %v", m.Game.Phase)
+		}
+		model, _ := m.Update(key)
+		next, isGame := model.(blackjackModel)
+		if !isGame {
+			t.Fatalf("left the table after %d rounds with %d chips", m.Auto.Rounds, m.Game.PlayerMoney)
+		}
+		m = next
+		if m.Game.Phase == phasePlay && (m.Game.Bet < 10 || m.Game.Bet > 50) {
+			t.Fatalf("bet %d outside the table limits", m.Game.Bet)
+		}
+	}
+	// the last key recorded the round, every card of the shoe so far is face up
+	if m.Auto.Bettor.Running != m.Game.HiLoCount {
+		t.Errorf("the bettor counts %d, the table %d", m.Auto.Bettor.Running, m.Game.HiLoCount)
+	}
+}
+
+func TestAutoplayKeys(t *testing.T) {
+	m := tableModel(t, gameState{Phase: phaseBet, NumberDecks: 1, Payout: 15, PlayerMoney: startingMoney})
+	model, cmd := handleAutoKey(m, "a")
+	m = model.(blackjackModel)
+	if !m.Auto.On || cmd == nil || m.Auto.Pace != autoDefaultPace {
+		t.Fatalf("autoplay on %v, pace %v", m.Auto.On, m.Auto.Pace)
+	}
+	stale := customAutoTickMsg{generation: m.Auto.Generation}
+
+	model, cmd = handleAutoKey(m, "p")
+	m = model.(blackjackModel)
+	if !m.Auto.Paused || cmd != nil {
+		t.Error("p does not pause")
+	}
+	model, cmd = handleAutoTick(m, stale)
+	if cmd != nil || model.(blackjackModel).Game.Phase != phaseBet {
+		t.Error("a tick from before the pause still plays")
+	}
+
+	for range 20 {
+		model, _ = handleAutoKey(m, "+")
+		m = model.(blackjackModel)
+	}
+	if m.Auto.Pace != autoMinPace {
+		t.Errorf("pace %v after speeding up, want the minimum %v", m.Auto.Pace, autoMinPace)
+	}
+	for range 20 {
+		model, _ = handleAutoKey(m, "-")
+		m = model.(blackjackModel)
+	}
+	if m.Auto.Pace != autoMaxPace {
+		t.Errorf("pace %v after slowing down, want the maximum %v", m.Auto.Pace, autoMaxPace)
+	}
+
+	policy := m.Auto.Policy
+	model, _ = handleAutoKey(m, "b")
+	if model.(blackjackModel).Auto.Policy == policy {
+		t.Error("b does not change the bet policy")
+	}
+	model, _ = handleAutoKey(m, "a")
+	if model.(blackjackModel).Auto.On {
+		t.Error("a does not stop autoplay")
+	}
+}
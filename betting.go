@@ -0,0 +1,152 @@
+package main
+
+import (
+	"math"
+	"sort"
+)
+
+// ------------------- Bet Policies ---------------------------
+
+// flatPolicy bets one unit, whatever happened before
+func flatPolicy(v betView) int {
+	return v.Unit
+}
+
+// rampPolicy spreads from one unit at a true count of 1 or less up to Spread units,
+// two more units per true count: 2 units at +2, 4 at +3, 6 at +4 and so on
+func rampPolicy(v betView) int {
+	units := 2 * (int(math.Floor(v.TrueCount)) - 1)
+	return v.Unit * max(1, min(units, v.Spread))
+}
+
+// kellyPolicy bets the Kelly fraction of the bankroll that the estimated edge allows.
+// Blackjack has a variance of about 1.3 bets squared per round, so full Kelly is edge/1.3.
+// Without an edge it bets the table minimum, sitting out isn't an option at this table.
+func kellyPolicy(v betView) int {
+	if v.Edge <= 0 {
+		return 0
+	}
+	return int(v.KellyFraction * float64(v.Bankroll) * v.Edge / blackjackVariance)
+}
+
+// martingalePolicy doubles after every loss and starts over after a win. It looks safe
+// until the table maximum or the bankroll stops the progression.
+func martingalePolicy(v betView) int {
+	switch {
+	case v.LastBet == 0 || v.LastNet > 0:
+		return v.Unit
+	case v.LastNet < 0:
+		return 2 * v.LastBet
+	default:
+		return v.LastBet
+	}
+}
+
+// paroliPolicy doubles after every win and starts over after a loss or three wins in a row
+func paroliPolicy(v betView) int {
+	switch {
+	case v.LastBet == 0 || v.LastNet < 0 || v.Wins%paroliWins == 0:
+		return v.Unit
+	case v.LastNet > 0:
+		return 2 * v.LastBet
+	default:
+		return v.LastBet
+	}
+}
+
+// clampBet keeps a bet inside the table limits and the bankroll, in whole chips of 10
+func clampBet(want, minBet, maxBet, bankroll int) int {
+	bet := max(minBet, min(want, maxBet, bankroll))
+	return bet - bet%10
+}
+
+// baselineEdge estimates the player edge off the top of a fresh shoe under basic strategy,
+// starting from the -0.5 % of a 6 deck S17 3:2 game. Blackjacks come in 4.75 % of the
+// rounds, so every tenth of the payout below 3:2 costs 0.475 %.
+func baselineEdge(payout uint8, hitOnSoft17 bool, numberDecks uint8) float64 {
+	edge := -0.005 - float64(15-int(payout))*0.00475
+	if hitOnSoft17 {
+		edge -= 0.002
+	}
+	switch {
+	case numberDecks == 1:
+		edge += 0.0048
+	case numberDecks == 2:
+		edge += 0.0019
+	case numberDecks <= 4:
+		edge += 0.0006
+	case numberDecks >= 8:
+		edge -= 0.0002
+	}
+	return edge
+}
+
+func betPolicyNames() []string {
+	names := make([]string, 0, len(betPolicies))
+	for name := range betPolicies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ------------------- Bettor ---------------------------------
+
+// newBettor keeps the Hi-Lo running count and the progression between rounds,
+// so the simulator and the auto-play can ask it for the next bet.
+func newBettor(policy betPolicy, unit, minBet, maxBet, spread int, kellyFraction, offTheTop float64) bettor {
+	return bettor{
+		Policy:        policy,
+		System:        builtinCountSystems[0],
+		Unit:          unit,
+		MinBet:        minBet,
+		MaxBet:        maxBet,
+		Spread:        spread,
+		KellyFraction: kellyFraction,
+		OffTheTop:     offTheTop,
+	}
+}
+
+// next returns the bet for the coming round. The shoe must already be reshuffled
+// if it needed to be, otherwise the count of the old shoe would size the bet.
+func (b *bettor) next(gs gameState, bankroll int) int {
+	if gs.RandomSeed != b.Seed || gs.CardsDealt < b.CardsDealt {
+		b.Seed = gs.RandomSeed
+		b.Running = 0
+	}
+	b.CardsDealt = gs.CardsDealt
+	tc := trueCount(b.Running, gs)
+	want := b.Policy(betView{
+		Unit:          b.Unit,
+		Spread:        b.Spread,
+		Bankroll:      bankroll,
+		LastBet:       b.LastBet,
+		LastNet:       b.LastNet,
+		Wins:          b.Wins,
+		TrueCount:     tc,
+		Edge:          b.OffTheTop + edgePerTrueCount*tc,
+		KellyFraction: b.KellyFraction,
+	})
+	return clampBet(want, b.MinBet, b.MaxBet, bankroll)
+}
+
+// record counts the cards of a finished round, every one of them is face up by then
+func (b *bettor) record(gs gameState, bet, net int) {
+	b.Running += b.System.runningCount(gs.PlayerCards) + b.System.runningCount(gs.DealerCards)
+	b.CardsDealt = gs.CardsDealt
+	b.LastBet = bet
+	b.LastNet = net
+	switch {
+	case net > 0:
+		b.Wins++
+	case net < 0:
+		b.Wins = 0
+	}
+}
+
+// restart forgets the progression after a ruin, the count of the shoe stays valid
+func (b *bettor) restart() {
+	b.LastBet = 0
+	b.LastNet = 0
+	b.Wins = 0
+}
@@ -0,0 +1,72 @@
+package main
+
+import "testing"
+
+func TestBetPolicies(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy betPolicy
+		view   betView
+		want   int
+	}{
+		{"flat", flatPolicy, betView{Unit: 10, TrueCount: 5}, 10},
+		{"ramp below +2", rampPolicy, betView{Unit: 10, Spread: 8, TrueCount: 1.9}, 10},
+		{"ramp at +3", rampPolicy, betView{Unit: 10, Spread: 8, TrueCount: 3.2}, 40},
+		{"ramp at the spread", rampPolicy, betView{Unit: 10, Spread: 8, TrueCount: 9}, 80},
+		{"ramp at a negative count", rampPolicy, betView{Unit: 10, Spread: 8, TrueCount: -4}, 10},
+		{"kelly without an edge", kellyPolicy, betView{Bankroll: 1000, Edge: -0.004, KellyFraction: 1}, 0},
+		{"kelly with an edge", kellyPolicy, betView{Bankroll: 13000, Edge: 0.01, KellyFraction: 0.5}, 50},
+		{"martingale first round", martingalePolicy, betView{Unit: 10}, 10},
+		{"martingale after a loss", martingalePolicy, betView{Unit: 10, LastBet: 40, LastNet: -40}, 80},
+		{"martingale after a push", martingalePolicy, betView{Unit: 10, LastBet: 40}, 40},
+		{"martingale after a win", martingalePolicy, betView{Unit: 10, LastBet: 40, LastNet: 40}, 10},
+		{"paroli after a win", paroliPolicy, betView{Unit: 10, LastBet: 20, LastNet: 20, Wins: 2}, 40},
+		{"paroli after three wins", paroliPolicy, betView{Unit: 10, LastBet: 40, LastNet: 40, Wins: paroliWins}, 10},
+		{"paroli after a loss", paroliPolicy, betView{Unit: 10, LastBet: 40, LastNet: -40}, 10},
+	}
+	for _, test := range tests {
+		got := test.policy(test.view)
+		if got != test.want {
+			t.Errorf("%s: got %d, want %d", test.name, got, test.want)
+		}
+	}
+}
+
+func TestClampBet(t *testing.T) {
+	tests := []struct{ want, bankroll, bet int }{
+		{0, 100, 10},   // the table minimum
+		{80, 100, 50},  // the table maximum
+		{40, 35, 30},   // the bankroll, in whole chips of 10
+		{37, 100, 30},  // whole chips of 10
+		{200, 500, 50}, // a martingale at the limit
+	}
+	for _, test := range tests {
+		got := clampBet(test.want, 10, 50, test.bankroll)
+		if got != test.bet {
+			t.Errorf("clampBet(%d) with %d chips: got %d, want %d", test.want, test.bankroll, got, test.bet)
+		}
+	}
+}
+
+func TestBettorCountsOneShoe(t *testing.T) {
+	b := newBettor(rampPolicy, 10, 10, 100, 8, 0.5, 0)
+	gs := gameState{NumberDecks: 1, RandomSeed: 7, CardsDealt: 4}
+	b.next(gs, 1000)
+	gs.PlayerCards = []card{{Rank: "2", Value: 2}, {Rank: "5", Value: 5}}
+	gs.DealerCards = []card{{Rank: "3", Value: 3}, {Rank: "6", Value: 6}}
+	gs.CardsDealt = 8
+	b.record(gs, 10, 10)
+	if b.Running != 4 || b.Wins != 1 {
+		t.Fatalf("running count %d, wins %d; want 4 and 1", b.Running, b.Wins)
+	}
+	// 4 with 44 cards left is a true count of 4.7, 6 units of the ramp
+	if got := b.next(gs, 1000); got != 60 {
+		t.Errorf("bet %d at a true count of %.1f, want 60", got, trueCount(b.Running, gs))
+	}
+
+	gs.RandomSeed = 8
+	gs.CardsDealt = 0
+	if got := b.next(gs, 1000); got != 10 || b.Running != 0 {
+		t.Errorf("bet %d with running count %d after a reshuffle, want 10 and 0", got, b.Running)
+	}
+}
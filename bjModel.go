@@ -1,17 +1,14 @@
 package main
 
 import (
-	"bufio"
 	"bytes"
 	"encoding/binary"
 	"fmt"
 	tea "github.com/charmbracelet/bubbletea"
 	"hash/crc32"
-	"io"
 	"log"
 	"math/rand/v2"
 	"os"
-	"path/filepath"
 	"slices"
 	"strconv"
 	"strings"
@@ -42,10 +39,10 @@ func (m blackjackModel) View() string {
 			return renderConfigStep(b, m, m.UiText.StartUpPrompt)
 
 		case configStepLoad:
-			return renderConfigStep(b, m, m.UiText.LoadPrompt)
+			return renderConfigStep(b, m, slotPrompt(m))
 
 		case configStepLoadFail:
-			return renderConfigStep(b, m, m.UiText.LoadFailStatus)
+			return renderConfigStep(b, m, m.UiText.LoadFailStatus+singleSpaceString+m.UiState.LoadError)
 
 		case configStepPayout:
 			return renderConfigStep(b, m, m.UiText.PayoutPrompt)
@@ -63,6 +60,9 @@ func (m blackjackModel) View() string {
 			return renderConfigStep(b, m, m.UiText.StartConfirmPrompt)
 
 		case configStepLoadConfirm:
+			if m.UiState.Notice != emptyString {
+				return renderConfigStep(b, m, m.UiText.LoadConfirmPrompt+newlineString+m.UiState.Notice)
+			}
 			return renderConfigStep(b, m, m.UiText.LoadConfirmPrompt)
 		}
 	}
@@ -73,6 +73,13 @@ func (m blackjackModel) View() string {
 	b = append(b, newlineRune)
 	b = append(b, newlineRune)
 
+	if m.Game.Phase == phaseQuiz {
+		return renderQuiz(b, m)
+	}
+	if m.UiState.ShowQR {
+		return renderQR(b, m)
+	}
+
 	if m.Game.Phase == phaseBet {
 		b = m.wrapAndPad(b, "Select Amount to Bet")
 		b = append(b, newlineRune)
@@ -83,7 +90,7 @@ func (m blackjackModel) View() string {
 		b = m.wrapAndPad(b, renderOptions(currentOptions(m), m.UiState.Cursor))
 		b = append(b, newlineRune)
 		b = append(b, newlineRune)
-		b = m.verticalPad(b)
+		b = m.renderFooter(b)
 		return string(b)
 	}
 
@@ -118,9 +125,30 @@ func (m blackjackModel) View() string {
 		b = append(b, newlineRune)
 	}
 
+	if m.Game.Phase == phasePlay && m.UiState.ShowHint {
+		b = m.wrapAndPad(b, hintText(m))
+		b = append(b, newlineRune)
+	}
+	if m.Game.Phase == phasePlay && m.UiState.ShowAnalysis {
+		for _, line := range analysisLines(m) {
+			b = m.wrapAndPad(b, line)
+			b = append(b, newlineRune)
+		}
+	}
+	if m.UiState.MistakeFeedback && m.UiState.LastMistake != emptyString {
+		b = m.wrapAndPad(b, m.UiState.LastMistake)
+		b = append(b, newlineRune)
+	}
+
 	if m.Game.Phase == phaseEnd {
 		b = m.wrapAndPad(b, m.UiState.Message)
 		b = append(b, newlineRune)
+		if m.UiState.MistakeFeedback {
+			for _, line := range mistakeSummary(m, 3) {
+				b = m.wrapAndPad(b, line)
+				b = append(b, newlineRune)
+			}
+		}
 		b = m.wrapAndPad(b, m.UiText.PromptConfirm)
 		b = append(b, newlineRune)
 		b = append(b, newlineRune)
@@ -133,10 +161,34 @@ func (m blackjackModel) View() string {
 	b = m.wrapAndPad(b, renderOptions(currentOptions(m), m.UiState.Cursor))
 	b = append(b, newlineRune)
 	b = append(b, newlineRune)
-	b = m.verticalPad(b)
+	b = m.renderFooter(b)
 	return string(b)
 }
 
+func (m blackjackModel) renderFooter(b []byte) []byte {
+	if m.UiState.ShowStats {
+		for _, line := range sessionLines(m) {
+			b = m.wrapAndPad(b, line)
+			b = append(b, newlineRune)
+		}
+	}
+	if m.Trainer.active() {
+		b = m.wrapAndPad(b, trainerLabel(m))
+		b = append(b, newlineRune)
+	}
+	if m.Auto.On {
+		b = m.wrapAndPad(b, autoLabel(m))
+		b = append(b, newlineRune)
+	}
+	if m.UiState.Notice != emptyString {
+		b = m.wrapAndPad(b, m.UiState.Notice)
+		b = append(b, newlineRune)
+	}
+	b = m.wrapAndPad(b, m.UiText.KeysHelp)
+	b = append(b, newlineRune)
+	return m.verticalPad(b)
+}
+
 func renderConfigStep(b []byte, m blackjackModel, promptText string) string {
 	b = m.wrapAndPad(b, promptText)
 	b = append(b, newlineRune)
@@ -179,6 +231,15 @@ func (m blackjackModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.UiState.hideCursorPending = false
 		return m, tea.HideCursor
 
+	case customAutoTickMsg:
+		return handleAutoTick(m, message)
+
+	case customAnalysisMsg:
+		m.UiState.Analysis = message.evs
+		m.UiState.AnalysisHand = message.hand
+		m.UiState.AnalysisReady = true
+		return m, nil
+
 	default:
 		return m, nil
 	}
@@ -186,9 +247,19 @@ func (m blackjackModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 func keyPressBlackjack(m blackjackModel, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
+	if m.Game.Phase == phaseQuiz && msg.Type != tea.KeyCtrlC {
+		return handleQuizKey(m, msg)
+	}
+	if m.Game.Phase == phaseConfig && m.Game.ConfigStep == configStepLoad && msg.Type != tea.KeyCtrlC {
+		model, cmd, handled := handleSlotKey(m, msg)
+		if handled {
+			return model, cmd
+		}
+	}
+
 	switch msg.Type {
 	case tea.KeyCtrlC:
-		return m, tea.Quit
+		return m, tea.Quit // exitGame saves a hand in progress
 
 	case tea.KeyUp:
 		if m.UiState.Cursor >= 1 {
@@ -204,12 +275,19 @@ func keyPressBlackjack(m blackjackModel, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case tea.KeyEnter:
 		selected := currentOptions(m)[m.UiState.Cursor]
+		m.UiState.Notice = emptyString
 		if m.Game.Phase == phaseConfig {
 			return handleConfigSelection(m, selected)
 		} else if m.Game.Phase == phaseBet {
-			return handleBetSelection(m, selected)
+			m.Session.MoneyBefore = m.Game.PlayerMoney
+			m.Session.Recorded = false
+			m.UiState.Saved = false
+			return withSessionRecord(handleBetSelection(m, selected))
 		} else {
-			return handleSelection(m, selected)
+			if optionAction(m, selected) != 0 {
+				m.UiState.Saved = false
+			}
+			return withSessionRecord(handleSelection(m, selected))
 		}
 
 	case tea.KeyBackspace:
@@ -219,6 +297,12 @@ func keyPressBlackjack(m blackjackModel, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
+	case tea.KeyRunes:
+		if m.Game.Phase == phaseConfig {
+			return m, nil
+		}
+		return handleToggleKey(m, msg.String())
+
 	default:
 		return m, nil
 	}
@@ -229,9 +313,9 @@ func currentOptions(m blackjackModel) []string {
 	if m.Game.Phase == phasePlay || m.Game.Phase == phaseEnd {
 		switch {
 		case m.Game.Phase == phasePlay && m.Game.PlayerMoney < m.Game.Bet:
-			return []string{m.UiText.OptionHit, m.UiText.OptionStand, m.UiText.OptionSurrender}
+			return []string{m.UiText.OptionHit, m.UiText.OptionStand, m.UiText.OptionSurrender, m.UiText.SaveAndQuit}
 		case m.Game.Phase == phasePlay && m.Game.PlayerMoney >= m.Game.Bet:
-			return []string{m.UiText.OptionHit, m.UiText.OptionStand, m.UiText.OptionDouble, m.UiText.OptionSurrender}
+			return []string{m.UiText.OptionHit, m.UiText.OptionStand, m.UiText.OptionDouble, m.UiText.OptionSurrender, m.UiText.SaveAndQuit}
 		case m.Game.Phase == phaseEnd:
 			return []string{m.UiText.OptionRestart, m.UiText.OptionQuit, m.UiText.SaveAndQuit}
 		default:
@@ -256,22 +340,13 @@ func currentOptions(m blackjackModel) []string {
 
 	switch m.Game.ConfigStep {
 	case configStepStartUp:
-		return []string{m.UiText.StartNewGame, m.UiText.LoadOldGame}
+		return []string{m.UiText.StartNewGame, m.UiText.LoadOldGame, m.UiText.CountingDrill, m.UiText.LifetimeStats}
 
 	case configStepLoad:
-		if len(m.UiState.Saves) > 0 {
-			return paginateLines(m.UiState.Saves, m.UiState.LoadPage)
-		}
-		if !m.UiState.firstTime {
-			log.Print("repeat fail to load save file")
+		if len(m.UiState.Slots) == 0 {
 			return []string{m.UiText.SaveNotFoundNewInstead, m.UiText.OptionQuit}
 		}
-
-		saves, err := loadSaveFile()
-		if err != nil || len(saves) == 0 {
-			return []string{m.UiText.SaveNotFoundNewInstead, m.UiText.OptionQuit}
-		}
-		return paginateLines(saves, m.UiState.LoadPage)
+		return paginateLines(slotLabels(m.UiText, m.UiState.Slots), m.UiState.LoadPage)
 
 	case configStepLoadFail:
 		return []string{m.UiText.StartNewGame, m.UiText.OptionQuit}
@@ -308,10 +383,19 @@ func handleConfigSelection(m blackjackModel, selected string) (tea.Model, tea.Cm
 	switch m.Game.ConfigStep {
 
 	case configStepStartUp:
-		if selected == m.UiText.StartNewGame {
+		switch selected {
+		case m.UiText.StartNewGame:
 			m.Game.ConfigStep = configStepPayout
-		} else {
+		case m.UiText.CountingDrill:
+			return newDrillModel(m), nil
+		case m.UiText.LifetimeStats:
+			model := newStatsModel(m)
+			return model, model.Init()
+		default:
 			m.Game.ConfigStep = configStepLoad
+			m.UiState.Slots = loadSlots()
+			m.UiState.LoadPage = 0
+			m.UiState.Notice = emptyString
 		}
 		m.UiState.Cursor = 0
 		return m, nil
@@ -321,7 +405,16 @@ func handleConfigSelection(m blackjackModel, selected string) (tea.Model, tea.Cm
 
 	case configStepLoadConfirm, configStepStartConfirm:
 		m.Game.Phase = phaseBet
-		return m, nil
+		if len(m.Game.PlayerCards) > 0 {
+			// a hand saved mid-round goes on where it was left, its bet is already paid
+			m.Game.Phase = phasePlay
+			m.Session.MoneyBefore = m.Game.PlayerMoney + m.Game.Bet
+			m.UiState.Saved = true
+		} else {
+			// a save between rounds may be past the reshuffle point, even with no card left
+			m.Game = reshuffleIfNeeded(m.Game)
+		}
+		return m, refreshAnalysis(m)
 
 	case configStepLoadFail:
 		if selected == m.UiText.OptionQuit {
@@ -333,14 +426,7 @@ func handleConfigSelection(m blackjackModel, selected string) (tea.Model, tea.Cm
 		return m, nil
 
 	case configStepPayout:
-		switch selected {
-		case payout32:
-			m.Game.Payout = 15
-		case payout75:
-			m.Game.Payout = 14
-		case payout65:
-			m.Game.Payout = 12
-		}
+		m.Game.Payout = payoutFromLabel(selected)
 		m.Game.ConfigStep = configStepH17
 		m.UiState.Cursor = 0
 		return m, nil
@@ -354,7 +440,7 @@ func handleConfigSelection(m blackjackModel, selected string) (tea.Model, tea.Cm
 	case configStepDecks:
 		match := regexIntegers.FindString(selected)
 		deckCount, err := strconv.Atoi(match)
-		if err != nil || deckCount < 1 || deckCount > 255 {
+		if err != nil || deckCount < 1 || deckCount > maxDecks {
 			log.Println("Failed to extract number of decks from " + selected)
 			deckCount = 1
 		}
@@ -396,10 +482,15 @@ func handleConfigStepLoad(m blackjackModel, selected string) (tea.Model, tea.Cmd
 		m.UiState.Cursor = 0
 		return m, nil
 	}
-	loadedGameState, err := decodeGameState(selected)
-	log.Print(err)
+	i := slices.IndexFunc(m.UiState.Slots, func(s saveSlot) bool { return slotLabel(m.UiText, s) == selected })
+	if i < 0 {
+		return m, nil
+	}
+	loadedGameState, repairs, err := decodeGameState(m.UiState.Slots[i].saveString())
 	if err != nil {
+		log.Print(err)
 		m.Game.ConfigStep = configStepLoadFail
+		m.UiState.LoadError = loadErrorReason(m.UiText, err)
 		m.UiState.Cursor = 0
 		return m, nil
 	}
@@ -407,19 +498,24 @@ func handleConfigStepLoad(m blackjackModel, selected string) (tea.Model, tea.Cmd
 	m.Game.RandomSeed = loadedGameState.RandomSeed
 	m.Game.ReshuffleThreshold = loadedGameState.ReshuffleThreshold
 	m.Game.CardsDealt = loadedGameState.CardsDealt
+	m.Game.HiLoCount = loadedGameState.HiLoCount
 	m.Game.NumberDecks = loadedGameState.NumberDecks
 	m.Game.HitOnSoft17 = loadedGameState.HitOnSoft17
 	m.Game.NeedReshuffle = loadedGameState.NeedReshuffle
 	m.Game.PlayerMoney = loadedGameState.PlayerMoney
 	m.Game.Payout = loadedGameState.Payout
-	totalCards := uint16(m.Game.NumberDecks) * 52
-	drawStack := make([]card, 0, totalCards)
-	m.Game.DrawStack, m.Game.RandomSeed = newDeck(m.Game.NumberDecks, drawStack, m.Game.RandomSeed)
-	m.Game.DrawStack = m.Game.DrawStack[m.Game.CardsDealt:]
-	playerCards := make([]card, 0, 22) // 22xA
-	dealerCards := make([]card, 0, 13) // 7xA + 1x5 + 5xA
-	m.Game.PlayerCards = playerCards
-	m.Game.DealerCards = dealerCards
+	m.Game.DrawStack = loadedGameState.DrawStack
+	m.Game.PlayerCards = loadedGameState.PlayerCards
+	m.Game.DealerCards = loadedGameState.DealerCards
+	m.Game.PlayerTotal = loadedGameState.PlayerTotal
+	m.Game.DealerTotal = loadedGameState.DealerTotal
+	m.Game.IsSoft17 = loadedGameState.IsSoft17
+	m.Game.Bet = loadedGameState.Bet
+	m.Game.Turn = loadedGameState.Turn
+	m.Slot = m.UiState.Slots[i].Name
+	if len(repairs) > 0 {
+		m.UiState.Notice = m.UiText.LoadRepaired + strings.Join(repairs, "; ")
+	}
 
 	m.Game.ConfigStep = configStepLoadConfirm
 	m.UiState.Cursor = 0
@@ -442,16 +538,18 @@ func handleConfigStepPen(m blackjackModel, selected string) (tea.Model, tea.Cmd)
 		penetration = 50
 	}
 	totalCards := uint16(m.Game.NumberDecks) * 52
-	m.Game.ReshuffleThreshold = (totalCards * uint16(penetration)) / 100
+	m.Game.ReshuffleThreshold = reshuffleThreshold(m.Game.NumberDecks, penetration)
 	drawStack := make([]card, 0, totalCards)
 	m.Game.DrawStack, m.Game.RandomSeed = newDeck(m.Game.NumberDecks, drawStack)
 	playerCards := make([]card, 0, 22) // 22xA
 	dealerCards := make([]card, 0, 13) // 7xA + 1x5 + 5xA
-	m.Game.PlayerMoney = 100
+	m.Game.PlayerMoney = startingMoney
 	m.Game.PlayerCards = playerCards
 	m.Game.DealerCards = dealerCards
 	m.Game.CardsDealt = 0
+	m.Game.HiLoCount = 0
 	m.Game.NeedReshuffle = false
+	m.Slot = uniqueSlotName(readSlots(), m.UiText.SlotDefaultName)
 	m.Game.ConfigStep = configStepStartConfirm
 	m.UiState.Cursor = 0
 	return m, nil
@@ -470,9 +568,9 @@ func handleBetSelection(m blackjackModel, selected string) (tea.Model, tea.Cmd)
 	case bet50:
 		m.Game.Bet = 50
 	}
-	m.Game.PlayerMoney -= m.Game.Bet
+	m.Game = placeBet(m.Game, m.Game.Bet)
 	startNewGameModel := gameModel(m)
-	return startNewGameModel, startNewGameModel.Init()
+	return startNewGameModel, tea.Batch(startNewGameModel.Init(), refreshAnalysis(startNewGameModel))
 }
 
 func handleSelection(m blackjackModel, selected string) (tea.Model, tea.Cmd) {
@@ -484,9 +582,15 @@ func handleSelection(m blackjackModel, selected string) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	action := optionAction(m, selected)
+	if m.Game.Phase == phasePlay && action != 0 {
+		m = reviewDecision(m, action)
+	}
+
 	switch selected {
 	case m.UiText.OptionHit:
-		return hit(m, normalLoose), nil
+		m = hit(m, normalLoose)
+		return m, refreshAnalysis(m)
 
 	case m.UiText.OptionStand:
 		return stand(m, normalWin, normalLoose, normalDraw), nil
@@ -499,27 +603,28 @@ func handleSelection(m blackjackModel, selected string) (tea.Model, tea.Cmd) {
 		return stand(m, doubleWin, doubleLoose, doubleDraw), nil
 
 	case m.UiText.OptionSurrender:
-		m.Game.ShowDealerHand = true
-		m.Game.Phase = phaseEnd
+		m.Game = playerSurrender(m.Game)
 		m.UiState.Cursor = 0
-		m.UiState.Message = m.UiText.Surrendered
-		m.Game.PlayerMoney, m.UiState.Message = calculateMoney(m.Game.Payout, m.Game.Bet, m.Game.PlayerMoney, m.UiState.Message, surrender)
+		m.UiState.Message = m.withBrokeNote(m.UiText.Surrendered)
 		return m, nil
 
 	case m.UiText.OptionRestart:
 		if int(m.Game.PlayerMoney) < 10 {
+			storeSession(m)
 			model := gameOverModel{
+				Stats:        sessionLines(m),
 				WindowHeight: m.UiState.WindowHeight,
 				WindowWidth:  m.UiState.WindowWidth,
 			}
 			return model, model.Init()
 		}
-		if m.Game.NeedReshuffle {
-			m.Game.DrawStack, m.Game.RandomSeed = newDeck(m.Game.NumberDecks, m.Game.DrawStack)
-			m.Game.NeedReshuffle = false
-			m.Game.CardsDealt = 0
+		if quizDue(m) {
+			return startQuiz(m), nil
 		}
-
+		if m.Trainer.active() {
+			m.Trainer.Rounds++
+		}
+		m.Game = reshuffleIfNeeded(m.Game)
 		m.Game.Phase = phaseBet
 		return m, nil
 
@@ -535,103 +640,63 @@ func handleSelection(m blackjackModel, selected string) (tea.Model, tea.Cmd) {
 }
 
 func hit(m blackjackModel, payoutType int) blackjackModel {
-	m.Game.PlayerCards, m.Game.DrawStack = drawOneFromStack(m.Game.PlayerCards, m.Game.DrawStack)
-	m.Game.CardsDealt++
-	m.Game.PlayerTotal, _ = calculateHand(m.Game.PlayerCards)
-
-	if m.Game.PlayerTotal >= 22 {
-		m.UiState.Message = m.UiText.PlayerBusts + strconv.Itoa(m.Game.PlayerTotal) + ". " + m.UiText.DealerWins
-		m.Game.ShowDealerHand = true
-		m.Game.Phase = phaseEnd
+	m.Game = playerHit(m.Game, payoutType)
+	if m.Game.Phase == phaseEnd {
 		m.UiState.Cursor = 0
-		m.Game.PlayerMoney, m.UiState.Message = calculateMoney(m.Game.Payout, m.Game.Bet, m.Game.PlayerMoney, m.UiState.Message, payoutType)
-	}
-
-	if m.Game.CardsDealt >= m.Game.ReshuffleThreshold {
-		m.Game.NeedReshuffle = true
+		m.UiState.Message = m.withBrokeNote(m.UiText.PlayerBusts + strconv.Itoa(m.Game.PlayerTotal) + ". " + m.UiText.DealerWins)
 	}
 	return m
 }
 
 func stand(m blackjackModel, winPayout, loosePayout, drawPayout int) blackjackModel {
-	m.Game.ShowDealerHand = true
-	m.Game.Turn = turnDealer
-
-	for m.Game.DealerTotal <= 16 || (m.Game.DealerTotal == 17 && m.Game.HitOnSoft17 && m.Game.IsSoft17) {
-		m.Game.DealerCards, m.Game.DrawStack = drawOneFromStack(m.Game.DealerCards, m.Game.DrawStack)
-		m.Game.CardsDealt++
-		m.Game.DealerTotal, m.Game.IsSoft17 = calculateHand(m.Game.DealerCards)
-	}
+	m.Game = dealerPlay(m.Game, winPayout, loosePayout, drawPayout)
 
 	switch {
 	case m.Game.DealerTotal >= 22:
 		m.UiState.Message = m.UiText.DealerBusts + strconv.Itoa(m.Game.DealerTotal) + ". " + m.UiText.PlayerWins
-		m.Game.PlayerMoney, m.UiState.Message = calculateMoney(m.Game.Payout, m.Game.Bet, m.Game.PlayerMoney, m.UiState.Message, winPayout)
 	case m.Game.PlayerTotal > m.Game.DealerTotal:
 		m.UiState.Message = m.UiText.PlayerWins + strconv.Itoa(m.Game.PlayerTotal) + " > " + strconv.Itoa(m.Game.DealerTotal)
-		m.Game.PlayerMoney, m.UiState.Message = calculateMoney(m.Game.Payout, m.Game.Bet, m.Game.PlayerMoney, m.UiState.Message, winPayout)
 	case m.Game.PlayerTotal < m.Game.DealerTotal:
 		m.UiState.Message = m.UiText.DealerWins + strconv.Itoa(m.Game.PlayerTotal) + " < " + strconv.Itoa(m.Game.DealerTotal)
-		m.Game.PlayerMoney, m.UiState.Message = calculateMoney(m.Game.Payout, m.Game.Bet, m.Game.PlayerMoney, m.UiState.Message, loosePayout)
 	case m.Game.PlayerTotal == m.Game.DealerTotal:
 		m.UiState.Message = m.UiText.Draw + strconv.Itoa(m.Game.PlayerTotal) + " = " + strconv.Itoa(m.Game.DealerTotal)
-		m.Game.PlayerMoney, m.UiState.Message = calculateMoney(m.Game.Payout, m.Game.Bet, m.Game.PlayerMoney, m.UiState.Message, drawPayout)
 	}
+	m.UiState.Message = m.withBrokeNote(m.UiState.Message)
 
-	m.Game.Phase = phaseEnd
 	m.UiState.Cursor = 0
-	if m.Game.CardsDealt >= m.Game.ReshuffleThreshold {
-		m.Game.NeedReshuffle = true
-	}
 	return m
 }
 
-func saveGameAndQuit(m blackjackModel) blackjackModel {
-	exePath, err := os.Executable()
-	if err != nil {
-		log.Printf("Error getting executable path: %v\n", err)
-		return m
+func (m blackjackModel) withBrokeNote(message string) string {
+	if m.Game.DealerBroke {
+		return message + dealerBrokeNote
 	}
-	exeDir := filepath.Dir(exePath)
-	saveFilePath := filepath.Join(exeDir, saveFile)
-	file, err := os.OpenFile(saveFilePath, os.O_RDWR|os.O_CREATE, 0777)
-	if err != nil {
-		log.Printf("Error opening/creating file: %v\n", err)
-		return m
-	}
-	defer func() {
-		closeErr := file.Close()
-		if closeErr != nil {
-			log.Println("Error while closing " + saveFilePath + ": " + closeErr.Error())
-		}
-	}()
-	existingContent, err := io.ReadAll(file)
-	if err != nil {
-		log.Printf("Error reading file: %v\n", err)
-		return m
-	}
-	existingContent, err = os.ReadFile(saveFilePath)
-	if err != nil {
-		log.Printf("Error reading existing file: %v\n", err)
-		return m
+	return message
+}
+
+func saveGameAndQuit(m blackjackModel) blackjackModel {
+	if m.Slot == emptyString {
+		m.Slot = uniqueSlotName(readSlots(), m.UiText.SlotDefaultName)
 	}
-	stringEncoded, err := m.Game.encodeWithChecksum()
-	if err != nil {
-		log.Fatalf("Base32 encoding failed: %v", err)
-	}
-	timestamp := time.Now().Format("2006-01-02-15:04:05")
-	lineToAdd := timestamp + singleSpaceString + stringEncoded + "\n"
-	newContent := make([]byte, 0, len([]byte(lineToAdd))+len(existingContent))
-	newContent = append(newContent, []byte(lineToAdd)...)
-	newContent = append(newContent, existingContent...)
-	err = os.WriteFile(saveFilePath, newContent, 0777)
+	err := storeSlot(m.Slot, m.Game, time.Now())
 	if err != nil {
 		log.Printf("Error writing to file: %v\n", err)
 		return m
 	}
+	m.UiState.Saved = true
 	return m
 }
 
+// exitGame runs however the program ends, by a key, SIGTERM or a closed terminal. The session
+// goes to the lifetime statistics and a hand in progress is saved, so quitting mid-hand
+// neither forfeits the bet nor takes it back.
+func exitGame(m blackjackModel) {
+	storeSession(m)
+	if m.Game.Phase == phasePlay && !m.UiState.Saved {
+		saveGameAndQuit(m)
+	}
+}
+
 func handleConfigBackstep(m blackjackModel) (tea.Model, tea.Cmd) {
 
 	switch m.Game.ConfigStep {
@@ -681,106 +746,105 @@ func handleConfigBackstep(m blackjackModel) (tea.Model, tea.Cmd) {
 func gameModel(m blackjackModel) blackjackModel {
 
 	m.UiState.Message = emptyString
-	m.Game.PlayerCards = m.Game.PlayerCards[:0]
-	m.Game.DealerCards = m.Game.DealerCards[:0]
-	m.Game.Turn = turnPlayer
+	m.UiState.LastMistake = emptyString
 	m.UiState.Cursor = 0
-	m.Game.Phase = phasePlay
-	m.Game.ShowDealerHand = false
-
-	m.Game.PlayerCards, m.Game.DrawStack = drawOneFromStack(m.Game.PlayerCards, m.Game.DrawStack)
-	m.Game.DealerCards, m.Game.DrawStack = drawOneFromStack(m.Game.DealerCards, m.Game.DrawStack)
-	m.Game.PlayerCards, m.Game.DrawStack = drawOneFromStack(m.Game.PlayerCards, m.Game.DrawStack)
-	m.Game.DealerCards, m.Game.DrawStack = drawOneFromStack(m.Game.DealerCards, m.Game.DrawStack)
-	m.Game.CardsDealt += 4
-	if m.Game.CardsDealt >= m.Game.ReshuffleThreshold {
-		m.Game.NeedReshuffle = true
-	}
-	m.Game.PlayerTotal, _ = calculateHand(m.Game.PlayerCards)
-	m.Game.DealerTotal, m.Game.IsSoft17 = calculateHand(m.Game.DealerCards)
-
-	if m.Game.PlayerTotal == 21 || m.Game.DealerTotal == 21 {
-		m.Game.ShowDealerHand = true
-		m.Game.Phase = phaseEnd
+	m.Game = dealRound(m.Game)
+
+	if m.Game.Phase == phaseEnd {
 		//goland:noinspection ALL
 		switch {
 		case m.Game.PlayerTotal == 21 && m.Game.DealerTotal != 21:
-			m.UiState.Message = m.UiText.NaturalBlackjackPlayer
-			m.Game.PlayerMoney, m.UiState.Message = calculateMoney(m.Game.Payout, m.Game.Bet, m.Game.PlayerMoney, m.UiState.Message, naturalBlackjackWin)
+			m.UiState.Message = m.withBrokeNote(m.UiText.NaturalBlackjackPlayer)
 		case m.Game.PlayerTotal != 21 && m.Game.DealerTotal == 21:
 			m.UiState.Message = m.UiText.NaturalBlackjackDealer
-			m.Game.PlayerMoney, m.UiState.Message = calculateMoney(m.Game.Payout, m.Game.Bet, m.Game.PlayerMoney, m.UiState.Message, normalLoose)
 		case m.Game.PlayerTotal == 21 && m.Game.DealerTotal == 21:
 			m.UiState.Message = m.UiText.NaturalBlackjackDraw
-			m.Game.PlayerMoney, m.UiState.Message = calculateMoney(m.Game.Payout, m.Game.Bet, m.Game.PlayerMoney, m.UiState.Message, normalDraw)
 		}
 	}
 	return m
 }
 
-func calculateMoney(payout uint8, bet, playerMoney uint16, message string, outcome int) (uint16, string) {
-	if playerMoney >= 65000 && (outcome == naturalBlackjackWin || outcome == normalWin) {
-		message += " But Dealer is broke - no more winnable money - GG"
-		return playerMoney, message
-	}
-
-	switch outcome {
-
-	case naturalBlackjackWin:
-		part := uint16(10 + payout)
-		playerMoney += bet * part / 10 // ⚠️ integer division
-	case normalWin:
-		playerMoney += bet * 2
-	case doubleWin:
-		playerMoney += bet * 3
-	case normalDraw:
-		playerMoney += bet
-	case doubleDraw:
-		playerMoney += bet
-	case normalLoose:
-		// no change to playerMoney
-		// due to starting bet. You already lost the starting bet, as such no change.
-		// If you draw you get your bet back etc.
-	case doubleLoose:
-		playerMoney -= bet
-	case surrender:
-		playerMoney += bet / 2
-	}
-
-	return playerMoney, message
-}
-
 // ------------------- Supporting Functions -------------------
 
 func loadSaveFile() ([]string, error) {
-	file, err := os.Open(saveFile)
-	if err != nil {
-		return nil, fmt.Errorf("error opening %s: %w", saveFile, err)
-	}
-	defer func() {
-		closeErr := file.Close()
-		if closeErr != nil {
-			log.Printf("error closing %s: %v", saveFile, closeErr)
-		}
-	}()
+	return recoverSaveFile(store.path(saveFile))
+}
 
-	var lines []string
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line != emptyString {
-			lines = append(lines, line)
+// rotateSaveBackups shifts save.txt.1 .. save.txt.(saveBackups-1) up by one and writes the
+// content about to be replaced as save.txt.1, the oldest backup falls off the end
+func rotateSaveBackups(filename string, content []byte) error {
+	for i := saveBackups - 1; i >= 1; i-- {
+		err := os.Rename(saveBackupName(filename, i), saveBackupName(filename, i+1))
+		if err != nil && !os.IsNotExist(err) {
+			return err
 		}
 	}
+	return writeFileAtomic(saveBackupName(filename, 1), content)
+}
 
-	err = scanner.Err()
+func saveBackupName(filename string, i int) string {
+	return filename + "." + strconv.Itoa(i)
+}
+
+// recoverSaveFile reads the save lines, newest first. A save file without its last newline or
+// without any content was cut off while it was written; its complete lines are merged with the
+// newest intact backup and written back, so a crash costs at most the save it interrupted.
+func recoverSaveFile(filename string) ([]string, error) {
+	lines, torn, err := readSaveLines(filename)
 	if err != nil {
-		return nil, fmt.Errorf("error scanning %s: %w", saveFile, err)
+		return nil, err
+	}
+	if !torn {
+		return lines, nil
 	}
 
+	for i := 1; i <= saveBackups; i++ {
+		backup, backupTorn, err := readSaveLines(saveBackupName(filename, i))
+		if err != nil || backupTorn {
+			continue
+		}
+		for _, line := range backup {
+			if !slices.Contains(lines, line) {
+				lines = append(lines, line)
+			}
+		}
+		log.Printf("Recovered %s with %s", filename, saveBackupName(filename, i))
+		break
+	}
+	if len(lines) == 0 {
+		return lines, nil
+	}
+	err = writeFileAtomic(filename, []byte(strings.Join(lines, "\n")+"\n"))
+	if err != nil {
+		log.Printf("Error writing the recovered %s: %v", filename, err)
+	}
 	return lines, nil
 }
 
+// readSaveLines returns the non-empty lines of a save file. torn reports an empty file or a
+// last line without its newline, which is dropped because it may be cut short.
+func readSaveLines(filename string) (lines []string, torn bool, err error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, false, fmt.Errorf("error opening %s: %w", filename, err)
+	}
+	if len(content) == 0 {
+		return nil, true, nil
+	}
+	torn = content[len(content)-1] != '\n'
+	rawLines := strings.Split(string(content), "\n")
+	if torn {
+		rawLines = rawLines[:len(rawLines)-1]
+	}
+	for _, rawLine := range rawLines {
+		line := strings.TrimSpace(rawLine)
+		if line != emptyString {
+			lines = append(lines, line)
+		}
+	}
+	return lines, torn, nil
+}
+
 func newDeck(deckCount uint8, drawStack []card, seed ...uint32) ([]card, uint32) {
 
 	var usedSeed uint32
@@ -847,6 +911,22 @@ func calculateHand(hand []card) (total int, isSoft17 bool) {
 // ------------------- Save / Load Functionality --------------
 
 func (gs gameState) encodeWithChecksum() (string, error) {
+	buf := gs.savePayload()
+	encoded := encodeBase45(buf)
+	// 20-bit truncated CRC32 ~99.9999% accuracy (1 in ~1M collision rate)
+	checksum := crc32.ChecksumIEEE(buf) & 0xFFFFF // 0xFFFFF = 1048575 = 20 bits
+	checksumStr := encodeBase32From20Bits(checksum)
+	return encoded + equalSignString + checksumStr, nil
+}
+
+// encodeWithMAC replaces the checksum by a MAC of the install secret, see saveMAC
+func (gs gameState) encodeWithMAC(key []byte) string {
+	buf := gs.savePayload()
+	return encodeBase45(buf) + equalSignString + saveMAC(key, buf)
+}
+
+// savePayload lays the savable fields out in the layout of saveVersion
+func (gs gameState) savePayload() []byte {
 	savableState := savableGameState{
 		RandomSeed:         gs.RandomSeed,
 		playerMoney:        gs.PlayerMoney,
@@ -857,11 +937,17 @@ func (gs gameState) encodeWithChecksum() (string, error) {
 		NeedReshuffle:      gs.NeedReshuffle,
 		Payout:             gs.Payout,
 	}
-	buf := make([]byte, 13)
-	binary.LittleEndian.PutUint32(buf[0:4], savableState.RandomSeed)
-	binary.LittleEndian.PutUint16(buf[4:6], savableState.ReshuffleThreshold)
-	binary.LittleEndian.PutUint16(buf[6:8], savableState.CardsDealt)
-	buf[8] = savableState.NumberDecks
+	if gs.Phase == phasePlay {
+		savableState.Bet = gs.Bet
+		savableState.PlayerCardCount = uint8(len(gs.PlayerCards))
+		savableState.Turn = uint8(gs.Turn)
+	}
+	buf := make([]byte, saveLayoutLengths[saveVersion])
+	buf[0] = saveVersion
+	binary.LittleEndian.PutUint32(buf[1:5], savableState.RandomSeed)
+	binary.LittleEndian.PutUint16(buf[5:7], savableState.ReshuffleThreshold)
+	binary.LittleEndian.PutUint16(buf[7:9], savableState.CardsDealt)
+	buf[9] = savableState.NumberDecks
 	var flags byte
 	if savableState.HitOnSoft17 {
 		flags |= 1
@@ -869,14 +955,13 @@ func (gs gameState) encodeWithChecksum() (string, error) {
 	if savableState.NeedReshuffle {
 		flags |= 2
 	}
-	buf[9] = flags
-	binary.LittleEndian.PutUint16(buf[10:12], savableState.playerMoney)
-	buf[12] = savableState.Payout
-	encoded := encodeBase45(buf)
-	// 20-bit truncated CRC32 ~99.9999% accuracy (1 in ~1M collision rate)
-	checksum := crc32.ChecksumIEEE(buf) & 0xFFFFF // 0xFFFFF = 1048575 = 20 bits
-	checksumStr := encodeBase32From20Bits(checksum)
-	return encoded + equalSignString + checksumStr, nil
+	buf[10] = flags
+	binary.LittleEndian.PutUint16(buf[11:13], savableState.playerMoney)
+	buf[13] = savableState.Payout
+	binary.LittleEndian.PutUint16(buf[14:16], savableState.Bet)
+	buf[16] = savableState.PlayerCardCount
+	buf[17] = savableState.Turn
+	return buf
 }
 
 func encodeBase45(data []byte) string {
@@ -901,7 +986,7 @@ func encodeBase45(data []byte) string {
 	temp = append(temp, data[leadingZeros:]...)
 	result := make([]byte, 0, len(temp)*146/100+1) // log(2)/log(45) ≈ 0.1821 base45 digits per bit ~ 1.46 characters per byte
 
-	for start := leadingZeros; start < len(temp); {
+	for start := 0; start < len(temp); { // temp starts after the leading zeros
 		remainder := divideBy45From(temp, start)
 		for start < len(temp) && temp[start] == 0 {
 			start++
@@ -949,7 +1034,7 @@ func decodeBase45(encoded string) ([]byte, error) {
 		return make([]byte, leadingZeros), nil
 	}
 
-	for i := 0; i < len(encoded); i++ {
+	for i := leadingZeros; i < len(encoded); i++ { // zeroRune is not part of the alphabet
 		r := encoded[i]
 		if base45Lookup[r] == -1 {
 			return []byte{}, fmt.Errorf("invalid character at position %d: not in Base45 alphabet", i)
@@ -1065,39 +1150,52 @@ func parseSaveString(input string) (timestamp, base45string, base32checksum stri
 	return timestamp, base45string, base32checksum, nil
 }
 
-func decodeGameState(saveString string) (gameState, error) {
+// decodeGameState also returns what validateSavable repaired, for the load screen to show
+func decodeGameState(saveString string) (gameState, []string, error) {
 	_, base45String, base32Checksum, err := parseSaveString(saveString)
 	if err != nil {
-		return gameState{}, fmt.Errorf("failed to parse save string: %v", err)
+		return gameState{}, nil, fmt.Errorf("failed to parse save string: %v", err)
 	}
 	decodedBase45, err := decodeBase45(base45String)
 	if err != nil {
-		return gameState{}, fmt.Errorf("failed to decode base45: %v", err)
+		return gameState{}, nil, fmt.Errorf("failed to decode base45: %v", err)
 	}
-	if len(decodedBase45) != 13 {
-		return gameState{}, fmt.Errorf("invalid data length: expected 13 bytes, got %d", len(decodedBase45))
+	err = verifySaveCheck(decodedBase45, base32Checksum)
+	if err != nil {
+		return gameState{}, nil, err
 	}
-	decodedBase32, err := decodeBase32To20Bits(base32Checksum)
+
+	buf, err := migrateSave(decodedBase45)
 	if err != nil {
-		return gameState{}, fmt.Errorf("failed to decode checksum: %v", err)
+		return gameState{}, nil, err
 	}
-	expectedChecksum := crc32.ChecksumIEEE(decodedBase45) & 0xFFFFF
-	if decodedBase32 != expectedChecksum {
-		return gameState{}, fmt.Errorf("checksum mismatch: expected %d, got %d", expectedChecksum, decodedBase32)
+	savableState, repairs, err := validateSavable(unpackSave(buf))
+	if err != nil {
+		return gameState{}, nil, err
 	}
+	gs, err := restoreGameState(savableState)
+	return gs, repairs, err
+}
 
-	buf := decodedBase45
-	savableState := savableGameState{
-		RandomSeed:         binary.LittleEndian.Uint32(buf[0:4]),
-		ReshuffleThreshold: binary.LittleEndian.Uint16(buf[4:6]),
-		CardsDealt:         binary.LittleEndian.Uint16(buf[6:8]),
-		NumberDecks:        buf[8],
-		HitOnSoft17:        (buf[9] & 1) != 0,
-		NeedReshuffle:      (buf[9] & 2) != 0,
-		playerMoney:        binary.LittleEndian.Uint16(buf[10:12]),
-		Payout:             buf[12],
+// unpackSave reads the fields of a save in the layout of saveVersion
+func unpackSave(buf []byte) savableGameState {
+	return savableGameState{
+		RandomSeed:         binary.LittleEndian.Uint32(buf[1:5]),
+		ReshuffleThreshold: binary.LittleEndian.Uint16(buf[5:7]),
+		CardsDealt:         binary.LittleEndian.Uint16(buf[7:9]),
+		NumberDecks:        buf[9],
+		HitOnSoft17:        (buf[10] & 1) != 0,
+		NeedReshuffle:      (buf[10] & 2) != 0,
+		playerMoney:        binary.LittleEndian.Uint16(buf[11:13]),
+		Payout:             buf[13],
+		Bet:                binary.LittleEndian.Uint16(buf[14:16]),
+		PlayerCardCount:    buf[16],
+		Turn:               buf[17],
 	}
+}
 
+// restoreGameState deals the shoe and a hand in progress back, the save has passed validateSavable
+func restoreGameState(savableState savableGameState) (gameState, error) {
 	gs := gameState{
 		RandomSeed:         savableState.RandomSeed,
 		PlayerMoney:        savableState.playerMoney,
@@ -1107,7 +1205,76 @@ func decodeGameState(saveString string) (gameState, error) {
 		HitOnSoft17:        savableState.HitOnSoft17,
 		NeedReshuffle:      savableState.NeedReshuffle,
 		Payout:             savableState.Payout,
+		Bet:                savableState.Bet,
+	}
+	gs = restoreShoe(gs, int(savableState.PlayerCardCount))
+	if gs.Phase == phasePlay && (gs.PlayerTotal >= 21 || gs.DealerTotal == 21) {
+		return gameState{}, fmt.Errorf("invalid hand in progress: player %d, dealer %d is already over", gs.PlayerTotal, gs.DealerTotal)
 	}
 
 	return gs, nil
 }
+
+// restoreShoe shuffles the shoe of RandomSeed again. For a save taken mid-hand it deals the hand
+// back from the last cards of CardsDealt in the order of dealRound and playerHit: player, dealer,
+// player, dealer, then the hits. A double or a stand always ends the hand, so only hits are pending.
+func restoreShoe(gs gameState, playerCards int) gameState {
+	totalCards := uint16(gs.NumberDecks) * 52
+	shoe, _ := newDeck(gs.NumberDecks, make([]card, 0, totalCards), gs.RandomSeed)
+	gs.DrawStack = shoe[gs.CardsDealt:]
+	gs.HiLoCount = builtinCountSystems[0].runningCount(shoe[:gs.CardsDealt])
+	gs.PlayerCards = make([]card, 0, 22) // 22xA
+	gs.DealerCards = make([]card, 0, 13) // 7xA + 1x5 + 5xA
+	if playerCards == 0 {
+		return gs
+	}
+	hand := shoe[int(gs.CardsDealt)-playerCards-2 : gs.CardsDealt]
+	gs.PlayerCards = append(gs.PlayerCards, hand[0], hand[2])
+	gs.PlayerCards = append(gs.PlayerCards, hand[4:]...)
+	gs.DealerCards = append(gs.DealerCards, hand[1], hand[3])
+	gs.PlayerTotal, _ = calculateHand(gs.PlayerCards)
+	gs.DealerTotal, gs.IsSoft17 = calculateHand(gs.DealerCards)
+	gs.Phase = phasePlay
+	gs.Turn = turnPlayer
+	return gs
+}
+
+// ------------------- Save Versions --------------------------
+
+// migrateSave upgrades the bytes of a save to the layout of saveVersion one version at a time.
+// Version 0 is the original 13-byte layout without a version byte, every later layout starts
+// with its version and only appends fields, so no versioned layout is ever 13 bytes long.
+func migrateSave(buf []byte) ([]byte, error) {
+	version := 0
+	if len(buf) != saveLayoutLengths[0] {
+		if len(buf) == 0 {
+			return nil, fmt.Errorf("invalid data length: got 0 bytes")
+		}
+		version = int(buf[0])
+	}
+	if version > saveVersion {
+		return nil, fmt.Errorf("save version %d is newer than this game (version %d)", version, saveVersion)
+	}
+	if len(buf) != saveLayoutLengths[version] {
+		return nil, fmt.Errorf("invalid data length: expected %d bytes for save version %d, got %d", saveLayoutLengths[version], version, len(buf))
+	}
+	for ; version < saveVersion; version++ {
+		buf = saveMigrations[version](buf)
+	}
+	return buf, nil
+}
+
+// migrateSaveV0 puts the version byte in front of the original layout
+func migrateSaveV0(buf []byte) []byte {
+	migrated := make([]byte, 0, saveLayoutLengths[1])
+	migrated = append(migrated, 1)
+	return append(migrated, buf...)
+}
+
+// migrateSaveV1 appends an empty hand in progress: no bet, no player cards, no turn
+func migrateSaveV1(buf []byte) []byte {
+	migrated := make([]byte, 0, saveLayoutLengths[2])
+	migrated = append(migrated, buf...)
+	migrated[0] = 2
+	return append(migrated, 0, 0, 0, 0)
+}
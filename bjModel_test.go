@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"hash/crc32"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// checksumCode encodes a payload of any layout the way encodeWithChecksum does
+func checksumCode(payload []byte) string {
+	return encodeBase45(payload) + equalSignString + encodeBase32From20Bits(crc32.ChecksumIEEE(payload)&0xFFFFF)
+}
+
+func decodeCode(t *testing.T, code string) gameState {
+	t.Helper()
+	gs, _, err := decodeGameState("2024-05-01-18:30:00 " + code)
+	if err != nil {
+		t.Fatalf("%s: %v", code, err)
+	}
+	return gs
+}
+
+// savedFields is what a save keeps of a table between rounds
+func savedFields(gs gameState) savableGameState {
+	return savableGameState{
+		RandomSeed:         gs.RandomSeed,
+		ReshuffleThreshold: gs.ReshuffleThreshold,
+		CardsDealt:         gs.CardsDealt,
+		NumberDecks:        gs.NumberDecks,
+		HitOnSoft17:        gs.HitOnSoft17,
+		NeedReshuffle:      gs.NeedReshuffle,
+		playerMoney:        gs.PlayerMoney,
+		Payout:             gs.Payout,
+	}
+}
+
+// The codes were written by the versions that introduced their layout
+func TestLegacySavesLoad(t *testing.T) {
+	tests := []struct {
+		layout string
+		code   string
+		want   savableGameState
+	}{
+		{"v0, 13 bytes", "41h6dHwEydKzDcXwtt1=CMCX",
+			savableGameState{RandomSeed: 123456789, ReshuffleThreshold: 234, CardsDealt: 100, NumberDecks: 6, HitOnSoft17: true, playerMoney: 250, Payout: 15}},
+		{"v0, 13 bytes", "XXeS1r5c2tzf8BCbXYJ=WT96",
+			savableGameState{RandomSeed: 987654321, ReshuffleThreshold: 78, CardsDealt: 80, NumberDecks: 2, NeedReshuffle: true, playerMoney: 1230, Payout: 14}},
+		{"v1, 14 bytes", "rPp98C9apKx9ZthCBWe=B4UF",
+			savableGameState{RandomSeed: 123456789, ReshuffleThreshold: 234, CardsDealt: 100, NumberDecks: 6, HitOnSoft17: true, playerMoney: 250, Payout: 15}},
+		{"v1, 14 bytes, seed with a zero low byte", "jS8KRCSskfwazeAh6Fy=AVW7",
+			savableGameState{RandomSeed: 0x12345600, ReshuffleThreshold: 26, CardsDealt: 30, NumberDecks: 1, NeedReshuffle: true, playerMoney: 100, Payout: 12}},
+		{"v1, 14 bytes, zero seed", "jP43SsDErgWAGDj3sRf=VYJ3",
+			savableGameState{NumberDecks: 2, playerMoney: 60, Payout: 14}},
+	}
+	for _, test := range tests {
+		gs := decodeCode(t, test.code)
+		if got := savedFields(gs); got != test.want {
+			t.Errorf("%s %s: got %+v, want %+v", test.layout, test.code, got, test.want)
+		}
+		if gs.Phase == phasePlay || len(gs.PlayerCards) > 0 || gs.Bet != 0 {
+			t.Errorf("%s %s: a legacy save has a hand in progress", test.layout, test.code)
+		}
+		if len(gs.DrawStack) != int(gs.NumberDecks)*52-int(gs.CardsDealt) {
+			t.Errorf("%s %s: %d cards left in the shoe", test.layout, test.code, len(gs.DrawStack))
+		}
+	}
+}
+
+func TestSaveRoundTripEveryLayout(t *testing.T) {
+	gs := gameState{RandomSeed: 0xA1B2C300, ReshuffleThreshold: 156, CardsDealt: 40, NumberDecks: 4, HitOnSoft17: true, PlayerMoney: 420, Payout: 12}
+	want := savedFields(gs)
+	code, err := gs.encodeWithChecksum()
+	if err != nil {
+		t.Fatal(err)
+	}
+	v2, err := decodeBase45(strings.Split(code, equalSignString)[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(v2) != saveLayoutLengths[2] || v2[0] != 2 {
+		t.Fatalf("the current layout is % x", v2)
+	}
+	v1 := append([]byte{1}, v2[1:saveLayoutLengths[1]]...)
+	v0 := slices.Clone(v2[1:saveLayoutLengths[1]])
+	for _, payload := range [][]byte{v0, v1, v2} {
+		got := savedFields(decodeCode(t, checksumCode(payload)))
+		if got != want {
+			t.Errorf("%d-byte layout: got %+v, want %+v", len(payload), got, want)
+		}
+	}
+}
+
+func TestMidHandSaveRoundTrip(t *testing.T) {
+	want := midHandGame(t)
+	code, err := want.encodeWithChecksum()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := decodeCode(t, code)
+	if savedFields(got) != savedFields(want) || got.Bet != want.Bet || got.Phase != phasePlay ||
+		!reflect.DeepEqual(got.PlayerCards, want.PlayerCards) || !reflect.DeepEqual(got.DealerCards, want.DealerCards) ||
+		!reflect.DeepEqual(got.DrawStack, want.DrawStack) {
+		t.Errorf("got player %v, dealer %v, bet %d; want player %v, dealer %v, bet %d",
+			got.PlayerCards, got.DealerCards, got.Bet, want.PlayerCards, want.DealerCards, want.Bet)
+	}
+}
+
+func TestMigrateSave(t *testing.T) {
+	v0 := []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13}
+	got, err := migrateSave(v0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := append(append([]byte{saveVersion}, v0...), 0, 0, 0, 0)
+	if !bytes.Equal(got, want) {
+		t.Errorf("v0 migrates to % x, want % x", got, want)
+	}
+	got, err = migrateSave(append([]byte{1}, v0...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("v1 migrates to % x, want % x", got, want)
+	}
+
+	for _, payload := range [][]byte{
+		nil,
+		{saveVersion + 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, // newer than this game
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},               // v1 with the length of v2
+		{2, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},                           // v2 with the length of v1
+	} {
+		_, err = migrateSave(payload)
+		if err == nil {
+			t.Errorf("% x migrates", payload)
+		}
+	}
+}
+
+func TestBase45LeadingZeros(t *testing.T) {
+	for _, data := range [][]byte{
+		{0},
+		{0, 0},
+		{0, 1},
+		{0, 0, 0, 255, 255},
+		{0, 0x56, 0x34, 0x12, 26, 0, 30, 0, 1, 2, 100, 0, 12}, // a v0 save of a seed with a zero low byte
+		{255, 0, 0, 0},
+		{2, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+	} {
+		got, err := decodeBase45(encodeBase45(data))
+		if err != nil {
+			t.Fatalf("% x: %v", data, err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("% x comes back as % x", data, got)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"strconv"
+)
+
+// ------------------- Bot Command ----------------------------
+
+// runBot lets a program in any language play the rules engine as a subprocess,
+// one JSON object per line in both directions. See README.md for the protocol.
+func runBot(args []string) int {
+	flags := flag.NewFlagSet(commandBot, flag.ContinueOnError)
+	err := flags.Parse(args)
+	if err != nil {
+		return 2
+	}
+	return botSession(os.Stdin, os.Stdout)
+}
+
+func botSession(r io.Reader, w io.Writer) int {
+	conn := botConn{
+		scanner: bufio.NewScanner(r),
+		encoder: json.NewEncoder(w),
+	}
+
+	gs, rng, err := botHandshake(&conn)
+	if err != nil {
+		conn.send(botError{Type: "error", Message: err.Error()})
+		return 2
+	}
+
+	reshuffled := true
+	for {
+		bet, err := botBet(&conn, gs, reshuffled)
+		if err != nil {
+			return botExitCode(err)
+		}
+		moneyBefore := int(gs.PlayerMoney)
+		gs = placeBet(gs, bet)
+		gs = dealRound(gs)
+
+		for gs.Phase == phasePlay {
+			action, err := botAction(&conn, gs)
+			if err != nil {
+				return botExitCode(err)
+			}
+			gs = applyAction(gs, action)
+		}
+
+		conn.send(botResult{
+			Type:        "result",
+			Outcome:     outcomeNames[gs.Outcome],
+			Net:         int(gs.PlayerMoney) - moneyBefore,
+			PlayerCards: gs.PlayerCards,
+			DealerCards: gs.DealerCards,
+			PlayerTotal: gs.PlayerTotal,
+			DealerTotal: gs.DealerTotal,
+			PlayerMoney: gs.PlayerMoney,
+			DealerBroke: gs.DealerBroke,
+		})
+		if gs.PlayerMoney < 10 {
+			conn.send(botOver{Type: "over", Reason: "broke", PlayerMoney: gs.PlayerMoney})
+			return 0
+		}
+		if gs.DealerBroke {
+			conn.send(botOver{Type: "over", Reason: "dealer broke", PlayerMoney: gs.PlayerMoney})
+			return 0
+		}
+
+		reshuffled = gs.NeedReshuffle
+		gs = reshuffleIfNeeded(gs, rng.Uint32())
+	}
+}
+
+// botHandshake expects {"type": "hello"} with optional rules, seed and money and answers
+// with the rules and the seed in effect. The same hello and the same actions replay the
+// same cards, the seed drives the first shoe and every reshuffle.
+func botHandshake(conn *botConn) (gameState, *rand.Rand, error) {
+	hello := botHello{
+		Rules: botRules{Payout: payout32, Decks: 6, Penetration: 75},
+		Money: startingMoney,
+	}
+	line, err := conn.read()
+	if err != nil {
+		return gameState{}, nil, fmt.Errorf("no hello: %w", err)
+	}
+	err = json.Unmarshal(line, &hello)
+	if err != nil || hello.Type != "hello" {
+		return gameState{}, nil, fmt.Errorf("the first line must be {\"type\": \"hello\"}")
+	}
+	if hello.Money < 10 || hello.Money > 60000 {
+		return gameState{}, nil, fmt.Errorf("money must be between 10 and 60000, got %d", hello.Money)
+	}
+	cfg, err := newSimConfig(1, hello.Rules.Payout, hello.Rules.HitOnSoft17, hello.Rules.Decks, hello.Rules.Penetration, "basic", 10, hello.Seed, 1)
+	if err != nil {
+		return gameState{}, nil, err
+	}
+
+	rng := rand.New(rand.NewPCG(cfg.Seed, simStream))
+	gs := newSimTable(cfg, rng)
+	gs.PlayerMoney = uint16(hello.Money)
+
+	hello.Protocol = botProtocolVersion
+	hello.Seed = cfg.Seed
+	conn.send(hello)
+	return gs, rng, nil
+}
+
+func botBet(conn *botConn, gs gameState, reshuffled bool) (uint16, error) {
+	bets := make([]int, 0, 5)
+	for bet := 10; bet <= min(50, int(gs.PlayerMoney)); bet += 10 {
+		bets = append(bets, bet)
+	}
+	state := botState{
+		Type:        "state",
+		Phase:       "bet",
+		PlayerCards: []card{},
+		DealerCards: []card{},
+		Legal:       []string{"bet"},
+		Bets:        bets,
+		PlayerMoney: gs.PlayerMoney,
+		CardsDealt:  gs.CardsDealt,
+		Reshuffled:  reshuffled,
+	}
+	for {
+		conn.send(state)
+		request, err := conn.request()
+		switch {
+		case errors.Is(err, errInvalidJSON):
+			conn.send(botError{Type: "error", Message: err.Error()})
+			continue
+		case err != nil:
+			return 0, err
+		}
+		if request.Action == "bet" && request.Amount%10 == 0 && request.Amount >= 10 && request.Amount <= bets[len(bets)-1] {
+			return uint16(request.Amount), nil
+		}
+		conn.send(botError{Type: "error", Message: "expected a bet of " + strconv.Itoa(bets[0]) + " to " + strconv.Itoa(bets[len(bets)-1]) + " in steps of 10"})
+	}
+}
+
+func botAction(conn *botConn, gs gameState) (int, error) {
+	legal := make([]string, 0, 4)
+	for _, action := range [4]int{actionHit, actionStand, actionDouble, actionSurrender} {
+		if legalAction(gs, action) {
+			legal = append(legal, actionNames[action])
+		}
+	}
+	state := botState{
+		Type:        "state",
+		Phase:       "play",
+		PlayerCards: gs.PlayerCards,
+		DealerCards: gs.DealerCards[:1], // the hole card stays hidden
+		PlayerTotal: gs.PlayerTotal,
+		Legal:       legal,
+		Bet:         gs.Bet,
+		PlayerMoney: gs.PlayerMoney,
+		CardsDealt:  gs.CardsDealt,
+	}
+	for {
+		conn.send(state)
+		request, err := conn.request()
+		switch {
+		case errors.Is(err, errInvalidJSON):
+			conn.send(botError{Type: "error", Message: err.Error()})
+			continue
+		case err != nil:
+			return 0, err
+		}
+		action := indexOf(actionNames[:], request.Action)
+		if action > 0 && legalAction(gs, action) {
+			return action, nil
+		}
+		conn.send(botError{Type: "error", Message: fmt.Sprintf("%q is not one of the legal actions %v", request.Action, legal)})
+	}
+}
+
+// ------------------- Bot Connection -------------------------
+
+func (c *botConn) read() ([]byte, error) {
+	for c.scanner.Scan() {
+		line := c.scanner.Bytes()
+		if len(line) > 0 {
+			return line, nil
+		}
+	}
+	err := c.scanner.Err()
+	if err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// request reads the next action. A quit action ends the session like the end of the input does.
+func (c *botConn) request() (botRequest, error) {
+	if c.failed != nil {
+		return botRequest{}, fmt.Errorf("error writing to the bot: %w", c.failed)
+	}
+	line, err := c.read()
+	if err != nil {
+		return botRequest{}, err
+	}
+	var request botRequest
+	err = json.Unmarshal(line, &request)
+	if err != nil {
+		return botRequest{}, fmt.Errorf("%w: %v", errInvalidJSON, err)
+	}
+	if request.Action == "quit" {
+		return botRequest{}, io.EOF
+	}
+	return request, nil
+}
+
+func (c *botConn) send(message any) {
+	err := c.encoder.Encode(message)
+	if err != nil {
+		c.failed = err
+	}
+}
+
+func botExitCode(err error) int {
+	if errors.Is(err, io.EOF) {
+		return 0
+	}
+	fmt.Fprintln(os.Stderr, "bot: "+err.Error())
+	return 1
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// playBot runs a bot session on the given input lines and returns its exit code and output lines
+func playBot(t *testing.T, input ...string) (int, []string) {
+	t.Helper()
+	var out bytes.Buffer
+	code := botSession(strings.NewReader(strings.Join(input, "\n")+"\n"), &out)
+	return code, strings.Split(strings.TrimSpace(out.String()), "\n")
+}
+
+// standEveryRound bets 10 and stands, a stand while a bet is due is answered with an error
+func standEveryRound(rounds int) []string {
+	input := []string{`{"type": "hello", "seed": 5, "rules": {"payout": "3:2", "decks": 2, "pen": 50}}`}
+	for range rounds {
+		input = append(input, `{"action": "bet", "amount": 10}`, `{"action": "stand"}`)
+	}
+	return append(input, `{"action": "quit"}`)
+}
+
+func TestBotSessionReplaysWithSeed(t *testing.T) {
+	code, first := playBot(t, standEveryRound(20)...)
+	if code != 0 {
+		t.Fatalf("exit code %d: %q", code, first)
+	}
+	_, second := playBot(t, standEveryRound(20)...)
+	if strings.Join(first, "\n") != strings.Join(second, "\n") {
+		t.Error("the same seed and actions dealt other cards")
+	}
+
+	var hello botHello
+	err := json.Unmarshal([]byte(first[0]), &hello)
+	if err != nil || hello.Seed != 5 || hello.Protocol != botProtocolVersion || hello.Rules.Decks != 2 {
+		t.Fatalf("handshake %q: %v", first[0], err)
+	}
+	results := 0
+	for _, line := range first[1:] {
+		var state botState
+		err = json.Unmarshal([]byte(line), &state)
+		if err != nil {
+			t.Fatalf("%q: %v", line, err)
+		}
+		switch {
+		case state.Type == "state" && state.Phase == "play" && len(state.DealerCards) != 1:
+			t.Errorf("the hole card is shown: %q", line)
+		case state.Type == "result":
+			results++
+		}
+	}
+	if results != 20 {
+		t.Errorf("%d results for 20 rounds", results)
+	}
+}
+
+func TestBotRejectsBadInput(t *testing.T) {
+	code, lines := playBot(t, `{"type": "hi"}`)
+	if code != 2 || len(lines) != 1 || !strings.Contains(lines[0], `"type":"error"`) {
+		t.Errorf("a wrong hello: exit code %d, %q", code, lines)
+	}
+	code, lines = playBot(t, `{"type": "hello", "money": 5}`)
+	if code != 2 || !strings.Contains(lines[0], "money") {
+		t.Errorf("too little money: exit code %d, %q", code, lines)
+	}
+
+	code, lines = playBot(t, `{"type": "hello", "seed": 1}`, `not json`, `{"action": "bet", "amount": 15}`, `{"action": "bet", "amount": 60}`)
+	if code != 0 {
+		t.Errorf("exit code %d at the end of the input", code)
+	}
+	rejected := 0
+	for _, line := range lines {
+		if strings.Contains(line, `"type":"error"`) {
+			rejected++
+		}
+	}
+	if rejected != 3 {
+		t.Errorf("%d errors for three bad requests: %q", rejected, lines)
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"github.com/aymanbagabas/go-osc52/v2"
+	tea "github.com/charmbracelet/bubbletea"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ------------------- Save Code Clipboard --------------------
+
+// copySaveCode puts the code on the clipboard with OSC 52, which works over SSH as well.
+// The sequence goes to stderr, the same terminal while Bubble Tea draws on stdout;
+// tmux and screen only pass it on when it is wrapped for them.
+func copySaveCode(code string) tea.Cmd {
+	return func() tea.Msg {
+		seq := osc52.New(code)
+		switch {
+		case os.Getenv("TMUX") != emptyString:
+			seq = seq.Tmux()
+		case strings.HasPrefix(os.Getenv("TERM"), "screen"):
+			seq = seq.Screen()
+		}
+		_, err := seq.WriteTo(os.Stderr)
+		if err != nil {
+			log.Println("Error writing the OSC 52 sequence: " + err.Error())
+		}
+		return nil
+	}
+}
+
+// copyGameCode copies the code of the game between rounds, the way Save and Quit would write it.
+// A hand in progress is refused, unlike a slot the code is not replaced by the outcome of the hand.
+func copyGameCode(m blackjackModel) (tea.Model, tea.Cmd) {
+	if m.Game.Phase == phasePlay {
+		m.UiState.Notice = m.UiText.CodeMidHand
+		return m, nil
+	}
+	code, err := encodeSave(m.Game)
+	if err != nil {
+		log.Println("Error encoding the save code: " + err.Error())
+		m.UiState.Notice = m.UiText.SlotWriteFailed + err.Error()
+		return m, nil
+	}
+	m.UiState.Notice = m.UiText.CodeCopied + code
+	return m, copySaveCode(code)
+}
+
+// importSaveCode loads a typed or pasted code: the bare "base45=check", a whole save line with
+// its timestamp, a line of save.txt with its slot name, or the text of a scanned QR code. It is checked like every save and
+// kept as a slot of its own before it is loaded.
+func importSaveCode(m blackjackModel, input string) (tea.Model, tea.Cmd) {
+	code, scanned := saveCodeFromQR(strings.TrimSpace(input))
+	if scanned {
+		input = code // the mapped payload may contain spaces
+	}
+	fields := strings.Fields(input)
+	i := slices.IndexFunc(fields, func(field string) bool { return strings.Contains(field, equalSignString) })
+	if i < 0 {
+		m.Game.ConfigStep = configStepLoadFail
+		m.UiState.LoadError = m.UiText.LoadFailInvalid + m.UiText.CodeMissing
+		m.UiState.Cursor = 0
+		return m, nil
+	}
+	slot := saveSlot{Time: time.Now().Format(saveTimeLayout), Code: fields[i]}
+	if i > 0 {
+		slot.Time = fields[i-1]
+	}
+	name := strings.Join(fields[i+1:], singleSpaceString)
+	if name == emptyString || name == autosaveSlot {
+		name = m.UiText.CodeImportedName
+	}
+	slot.Name = uniqueSlotName(m.UiState.Slots, name)
+
+	gs, _, err := decodeGameState(slot.saveString())
+	if err != nil {
+		log.Print(err)
+		m.Game.ConfigStep = configStepLoadFail
+		m.UiState.LoadError = loadErrorReason(m.UiText, err)
+		m.UiState.Cursor = 0
+		return m, nil
+	}
+	slot.Money = int(gs.PlayerMoney)
+	slot.Rules = rulesSummary(gs.Payout, gs.HitOnSoft17, gs.NumberDecks, penetrationOf(gs))
+	m.UiState.Slots = append([]saveSlot{slot}, m.UiState.Slots...)
+	m = writeSlotEdit(m, m.UiText.CodeImported+slot.Name)
+	return handleConfigStepLoad(m, slotLabel(m.UiText, slot))
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"strings"
+	"testing"
+)
+
+// tableModel is a game on the table with the English texts and a window large enough for a QR code
+func tableModel(t *testing.T, gs gameState) blackjackModel {
+	t.Helper()
+	text, err := loadUiStrings("en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := blackjackModel{Game: gs, UiText: finalizeUiStrings(text)}
+	m.UiState.WindowWidth, m.UiState.WindowHeight = 100, 60
+	return m
+}
+
+func TestNoCodeOfHandInProgress(t *testing.T) {
+	m := tableModel(t, midHandGame(t))
+	code, err := encodeSave(m.Game)
+	if err != nil {
+		t.Fatal(err)
+	}
+	model, cmd := copyGameCode(m)
+	if cmd != nil || model.(blackjackModel).UiState.Notice != m.UiText.CodeMidHand {
+		t.Errorf("copied a hand in progress: %q", model.(blackjackModel).UiState.Notice)
+	}
+	view := renderQR(nil, m)
+	if strings.Contains(view, code) || !strings.Contains(view, m.UiText.CodeMidHand) {
+		t.Errorf("the QR screen of a hand in progress shows %q", view)
+	}
+
+	m.Game = gameState{Phase: phaseBet, NumberDecks: 1, Payout: 15, PlayerMoney: 90, ReshuffleThreshold: reshuffleThreshold(1, 50)}
+	code, err = encodeSave(m.Game)
+	if err != nil {
+		t.Fatal(err)
+	}
+	model, cmd = copyGameCode(m)
+	if cmd == nil || model.(blackjackModel).UiState.Notice != m.UiText.CodeCopied+code {
+		t.Errorf("between rounds: %q", model.(blackjackModel).UiState.Notice)
+	}
+	if !strings.Contains(renderQR(nil, m), code) {
+		t.Error("the QR screen between rounds does not show the code")
+	}
+}
+
+func TestImportSaveCode(t *testing.T) {
+	gs := gameState{RandomSeed: 3, NumberDecks: 2, Payout: 15, PlayerMoney: 240, ReshuffleThreshold: reshuffleThreshold(2, 50)}
+	code, err := encodeSave(gs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	alphanumeric, tail := qrSaveText(code)
+	payload, _, _ := strings.Cut(code, equalSignString)
+	tests := []struct {
+		name  string
+		input string
+		slot  string // empty when the code is refused
+	}{
+		{"bare code", code, "Imported"},
+		{"save line", "2026-01-01-12:00:00 " + code, "Imported"},
+		{"slot line", "2026-01-01-12:00:00 " + code + "\tSunday game", "Sunday game"},
+		{"scanned QR code", alphanumeric + tail, "Imported"},
+		{"autosave name", code + " " + autosaveSlot, "Imported"},
+		{"wrong check", payload + "=AAAA", ""},
+		{"no code", "hello", ""},
+	}
+	for _, test := range tests {
+		resetStorage(t)
+		m := loadScreen(t)
+		m = pressKeys(t, m, runes("v"), runes(test.input), tea.KeyMsg{Type: tea.KeyEnter})
+		slots := readSlots()
+		if test.slot == emptyString {
+			if m.Game.ConfigStep != configStepLoadFail || len(slots) != 0 {
+				t.Errorf("%s: step %v with slots %v, want the code refused", test.name, m.Game.ConfigStep, slotNames(slots))
+			}
+			continue
+		}
+		if m.Game.ConfigStep != configStepLoadConfirm || len(slots) != 1 {
+			t.Errorf("%s: step %v with slots %v: %s", test.name, m.Game.ConfigStep, slotNames(slots), m.UiState.LoadError)
+			continue
+		}
+		if slots[0].Code != code || !strings.HasPrefix(slots[0].Name, test.slot) || m.Game.PlayerMoney != gs.PlayerMoney {
+			t.Errorf("%s: slot %+v with %d chips loaded", test.name, slots[0], m.Game.PlayerMoney)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"sort"
+	"strconv"
+)
+
+// ------------------- Strategy Coach -------------------------
+
+func handleToggleKey(m blackjackModel, key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "h":
+		m.UiState.ShowHint = !m.UiState.ShowHint
+	case "f":
+		m.UiState.MistakeFeedback = !m.UiState.MistakeFeedback
+	case "e":
+		m.UiState.ShowAnalysis = !m.UiState.ShowAnalysis
+		return m, refreshAnalysis(m)
+	case "t":
+		return cycleCountSystem(m), nil
+	case "i":
+		m.UiState.ShowStats = !m.UiState.ShowStats
+	case "y":
+		return copyGameCode(m)
+	case "q":
+		m.UiState.ShowQR = !m.UiState.ShowQR
+	case "a", "p", "+", "-", "s", "b":
+		return handleAutoKey(m, key)
+	}
+	return m, nil
+}
+
+// ------------------- EV Analysis Panel ----------------------
+
+func refreshAnalysis(m blackjackModel) tea.Cmd {
+	if !m.UiState.ShowAnalysis || m.Game.Phase != phasePlay {
+		return nil
+	}
+	return analyzeHand(m.Game)
+}
+
+func currentHand(gs gameState) handID {
+	return handID{RandomSeed: gs.RandomSeed, CardsDealt: gs.CardsDealt}
+}
+
+func analysisLines(m blackjackModel) []string {
+	if !m.UiState.AnalysisReady || m.UiState.AnalysisHand != currentHand(m.Game) {
+		return []string{m.UiText.AnalysisTitle, "  " + m.UiText.AnalysisPending}
+	}
+	evs := m.UiState.Analysis
+	doubleAllowed := canDouble(m.Game)
+	best := evs.bestAction(doubleAllowed)
+	lines := make([]string, 0, 5)
+	lines = append(lines, m.UiText.AnalysisTitle)
+	for _, action := range [4]int{actionHit, actionStand, actionDouble, actionSurrender} {
+		if action == actionDouble && !doubleAllowed {
+			continue
+		}
+		marker := "  "
+		if action == best {
+			marker = optionCursorPrefix
+		}
+		lines = append(lines, marker+actionOption(m, action)+": "+strconv.FormatFloat(evs[action], 'f', 3, 64))
+	}
+	return lines
+}
+
+func optionAction(m blackjackModel, selected string) int {
+	switch selected {
+	case m.UiText.OptionHit:
+		return actionHit
+	case m.UiText.OptionStand:
+		return actionStand
+	case m.UiText.OptionDouble:
+		return actionDouble
+	case m.UiText.OptionSurrender:
+		return actionSurrender
+	default:
+		return 0
+	}
+}
+
+func actionOption(m blackjackModel, action int) string {
+	switch action {
+	case actionHit:
+		return m.UiText.OptionHit
+	case actionStand:
+		return m.UiText.OptionStand
+	case actionDouble:
+		return m.UiText.OptionDouble
+	case actionSurrender:
+		return m.UiText.OptionSurrender
+	default:
+		return emptyString
+	}
+}
+
+// hintText shows basic strategy unless the true count of the cards seen so far triggers
+// an index play or the cards of the hand call for a composition-dependent play
+func hintText(m blackjackModel) string {
+	v := countedView(m.Game)
+	base := basicStrategy(v)
+	play, action, ok := deviation(v, base)
+	switch {
+	case ok && action != base:
+		return m.UiText.IndexPlayLabel + actionOption(m, action) + " (" + indexPlayName(play) + ", " +
+			m.UiText.CountTrue + strconv.FormatFloat(v.TrueCount, 'f', 1, 64) + ")"
+	case ok:
+		return m.UiText.HintLabel + actionOption(m, base) // the count decides, not the cards
+	}
+	composition := compositionStrategy(v)
+	if composition != base {
+		return m.UiText.CompositionLabel + actionOption(m, composition) + m.UiText.InsteadOf + actionOption(m, base)
+	}
+	return m.UiText.HintLabel + actionOption(m, base)
+}
+
+// reviewDecision compares the chosen option with basic strategy before it is played
+// and books the difference in expected value as the cost of the mistake.
+// Following an index play or a composition-dependent play the hint shows isn't a mistake.
+func reviewDecision(m blackjackModel, chosen int) blackjackModel {
+	m.UiState.LastMistake = emptyString
+	v := countedView(m.Game)
+	table := strategyTableFor(strategyKey{NumberDecks: v.NumberDecks, HitOnSoft17: v.HitOnSoft17, CanDouble: v.CanDouble})
+	evs := table.lookup(v)
+	best := evs.bestAction(v.CanDouble)
+	cost := (evs[best] - evs[chosen]) * float64(m.Game.Bet)
+	if chosen == best || cost < 1e-9 {
+		return m
+	}
+	_, action, ok := deviation(v, best)
+	if (ok && chosen == action) || chosen == compositionStrategy(v) {
+		return m
+	}
+
+	habit := handLabel(m, v) + m.UiText.Versus + upcardLabel(v.Upcard) + ": " +
+		actionOption(m, chosen) + m.UiText.InsteadOf + actionOption(m, best)
+	if m.UiState.Mistakes == nil {
+		m.UiState.Mistakes = make(map[string]mistakeTally)
+	}
+	tally := m.UiState.Mistakes[habit]
+	tally.Count++
+	tally.Cost += cost
+	m.UiState.Mistakes[habit] = tally
+	m.UiState.MistakeCount++
+	m.UiState.MistakeCost += cost
+	m.UiState.LastMistake = m.UiText.MistakeLabel + habit + " (-" + strconv.FormatFloat(cost, 'f', 2, 64) + ")"
+	return m
+}
+
+func handLabel(m blackjackModel, v handView) string {
+	if v.Soft {
+		return m.UiText.SoftHand + singleSpaceString + strconv.Itoa(v.PlayerTotal)
+	}
+	return m.UiText.HardHand + singleSpaceString + strconv.Itoa(v.PlayerTotal)
+}
+
+func upcardLabel(c card) string {
+	if c.Value == 10 {
+		return ten
+	}
+	return c.Rank
+}
+
+// mistakeSummary lists the session total and the costliest habits first
+func mistakeSummary(m blackjackModel, limit int) []string {
+	lines := make([]string, 0, limit+1)
+	lines = append(lines, m.UiText.MistakeSummary+strconv.Itoa(m.UiState.MistakeCount)+
+		" (-"+strconv.FormatFloat(m.UiState.MistakeCost, 'f', 2, 64)+")")
+
+	habits := make([]string, 0, len(m.UiState.Mistakes))
+	for habit := range m.UiState.Mistakes {
+		habits = append(habits, habit)
+	}
+	sort.Slice(habits, func(i, j int) bool {
+		a, b := m.UiState.Mistakes[habits[i]], m.UiState.Mistakes[habits[j]]
+		if a.Cost != b.Cost {
+			return a.Cost > b.Cost
+		}
+		return habits[i] < habits[j]
+	})
+	for i, habit := range habits {
+		if i == limit {
+			break
+		}
+		tally := m.UiState.Mistakes[habit]
+		lines = append(lines, "  "+habit+" x"+strconv.Itoa(tally.Count)+" (-"+strconv.FormatFloat(tally.Cost, 'f', 2, 64)+")")
+	}
+	return lines
+}
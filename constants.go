@@ -1,5 +1,7 @@
 package main
 
+import "time"
+
 const (
 	debugFile              = "debug.log"
 	emptyString            = ""
@@ -53,6 +55,7 @@ const (
 	phasePlay              = 2
 	phaseEnd               = 3
 	phaseBet               = 4
+	phaseQuiz              = 5
 	pen00                  = " 0 %"
 	pen25                  = "25 %"
 	pen50                  = "50 %"
@@ -73,6 +76,7 @@ const (
 	base32Chars = "123456789ABCDEFGHJKLMNPRSTUVWXYZ"
 	// Custom base45 character set: digits 1-9, uppercase and lowercase letters excluding i,l,m,n,o,q,u,v from both cases
 	base45Chars         = "123456789ABCDEFGHJKPRSTWXYZabcdefghjkprstwxyz"
+	noOutcome           = 0
 	naturalBlackjackWin = 1
 	normalWin           = 2
 	normalLoose         = 3
@@ -86,4 +90,72 @@ const (
 	bet30               = "30"
 	bet40               = "40"
 	bet50               = "50"
+	dealerBrokeNote     = " But Dealer is broke - no more winnable money - GG"
+	actionHit           = 1
+	actionStand         = 2
+	actionDouble        = 3
+	actionSurrender     = 4
+	commandSimulate     = "simulate"
+	commandStrategy     = "strategy"
+	actionLetters       = " HSDR"            // indexed by the action constants
+	simBankroll         = 30000              // scratch PlayerMoney per simulated round, far from the 65000 cap
+	simStream           = 0x9E3779B97F4A7C15 // second PCG word, the master seed is the first
+	histogramOffset     = 20                 // a double win or loss moves 20 tenths of the bet
+	simChunkRounds      = 1 << 15            // rounds of one table in simulate, the unit the workers share
+	ruinChunkSessions   = 64                 // sessions of one table in the ruin command, shared like simChunkRounds
+	countFile           = "count.json"
+	trainerInterval     = 3 // rounds between two count checks
+	drillFile           = "drill.txt"
+	drillSummaryWeeks   = 4 // weeks of drill history on the setup screen
+	drillStageSetup     = 0
+	drillStageFlash     = 1
+	drillStageAnswer    = 2
+	drillStageResult    = 3
+	drillTimeLayout     = saveTimeLayout
+	blackjackVariance   = 1.3   // squared bets per round under basic strategy
+	edgePerTrueCount    = 0.005 // player edge gained per Hi-Lo true count
+	paroliWins          = 3     // wins in a row before Paroli starts over
+	commandRuin         = "ruin"
+	startingMoney       = 100    // chips of a new game
+	ruinPilotRounds     = 500000 // rounds that measure mean and variance for the analytic ruin
+	ruinChartWidth      = 50     // checkpoints of the bankroll chart
+	ruinChartHeight     = 15
+	ruinHistogramBins   = 10
+	autoDefaultPace     = 500 * time.Millisecond
+	autoMinPace         = time.Millisecond
+	autoMaxPace         = 4 * time.Second
+	commandBot          = "bot"
+	botProtocolVersion  = 1
+	envObservationSize  = 26 // see observe in rlenv.go
+	envBet              = 10
+	statsFile           = "stats.json"
+	statsSchemaVersion  = 1
+	statsHistoryLimit   = 200 // sessions in the bankroll history
+	statsBestSessions   = 10
+	statsTimeLayout     = "2006-01-02 15:04"
+	saveVersion         = 2 // layout written by encodeWithChecksum, see migrateSave
+	appName             = "go-blackjack-tui"
+	dataDirEnv          = "BLACKJACK_DATA_DIR"
+	saveBackups         = 3 // save.txt.1 is the newest backup
+	saveModeCasual      = "casual"
+	saveModeMAC         = "mac"
+	saveMACLength       = 12 // three base32 groups of 20 bits
+	secretFile          = "secret.key"
+	secretLength        = 32
+	saveTimeLayout      = "2006-01-02-15:04:05"
+	autosaveSlot        = "Autosave" // reserved, renaming a slot to it is refused
+	slotNameLimit       = 24
+	slotEditNone        = 0
+	slotEditRename      = 1
+	slotEditDelete      = 2
+	slotEditCode        = 3
+	saveCodeLimit       = 120 // a save line with timestamp, MAC and a slot name fits
+	qrAlphanumeric      = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+	qrModeAlphanumeric  = 2
+	qrModeByte          = 4
+	qrLevelM            = 0 // format bits of error correction level M
+	commandInspect      = "inspect"
+	inspectNoTimestamp  = "-" // stands in for the timestamp of a bare code
+	maxDecks            = 255 // the largest shoe of the config screen, saves, the simulator and the bot protocol
+	smallestBet         = 10
 )
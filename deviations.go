@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// ------------------- Composition-Dependent Strategy ---------
+
+// compositionStrategy evaluates the actual cards of the hand instead of a typical hand
+// for its total, so 10-6 and 9-7 against a 10 can be played differently.
+func compositionStrategy(v handView) int {
+	return compositionEVs(v).bestAction(v.CanDouble)
+}
+
+func compositionEVs(v handView) actionEVs {
+	key := compositionKey{
+		Rules:  strategyKey{NumberDecks: v.NumberDecks, HitOnSoft17: v.HitOnSoft17, CanDouble: v.CanDouble},
+		Upcard: cardIndex(v.Upcard),
+	}
+	for _, c := range v.PlayerCards {
+		key.Cards[cardIndex(c)]++
+	}
+
+	compositionCacheMu.Lock()
+	evs, ok := compositionCache[key]
+	compositionCacheMu.Unlock()
+	if ok {
+		return evs
+	}
+
+	playerCards := make([]int, 0, len(v.PlayerCards))
+	for index, count := range key.Cards {
+		for range count {
+			playerCards = append(playerCards, index)
+		}
+	}
+	evs = evaluateStart(key.Rules, key.Upcard, playerCards...)
+
+	compositionCacheMu.Lock()
+	compositionCache[key] = evs
+	compositionCacheMu.Unlock()
+	return evs
+}
+
+// ------------------- Index Plays ----------------------------
+
+// deviation returns the index play for the hand if one applies at the true count of the
+// view. The plays are checked in the order of indexPlays, so the Fab 4 surrenders win over
+// the Illustrious 18 for the same hand, and like at a surrender table an Illustrious 18
+// play never replaces a surrender of the base strategy. Doubles only apply to the first two cards.
+func deviation(v handView, base int) (indexPlay, int, bool) {
+	if v.Soft {
+		return indexPlay{}, 0, false
+	}
+	upcard := cardIndex(v.Upcard)
+	for _, play := range indexPlays {
+		if play.Total != v.PlayerTotal || play.Upcard != upcard {
+			continue
+		}
+		action := play.Below
+		if v.TrueCount >= play.Index {
+			action = play.AtOrAbove
+		}
+		switch {
+		case action == 0, action == actionDouble && (!v.CanDouble || len(v.PlayerCards) != 2):
+			continue
+		case base == actionSurrender && play.AtOrAbove != actionSurrender:
+			continue
+		}
+		return play, action, true
+	}
+	return indexPlay{}, 0, false
+}
+
+func indexStrategy(v handView) int {
+	base := basicStrategy(v)
+	_, action, ok := deviation(v, base)
+	if ok {
+		return action
+	}
+	return base
+}
+
+func compositionIndexStrategy(v handView) int {
+	base := compositionStrategy(v)
+	_, action, ok := deviation(v, base)
+	if ok {
+		return action
+	}
+	return base
+}
+
+// handTrueCount is the Hi-Lo true count of what the player sees: the running count
+// before the round and the cards on the table, without a hidden hole card
+func handTrueCount(running int, gs gameState) float64 {
+	hiLo := builtinCountSystems[0]
+	running += hiLo.runningCount(gs.PlayerCards)
+	if gs.ShowDealerHand {
+		running += hiLo.runningCount(gs.DealerCards)
+	} else if len(gs.DealerCards) > 0 {
+		running += hiLo.tag(gs.DealerCards[0])
+	}
+	return trueCount(running, gs)
+}
+
+// countedView is viewOf with the true count of every card seen since the last shuffle
+func countedView(gs gameState) handView {
+	v := viewOf(gs)
+	v.TrueCount = handTrueCount(countBeforeHand(gs), gs)
+	return v
+}
+
+// countBeforeHand is the Hi-Lo running count of the shoe without the hand on the table
+func countBeforeHand(gs gameState) int {
+	if gs.Phase != phasePlay && gs.Phase != phaseEnd {
+		return gs.HiLoCount
+	}
+	hiLo := builtinCountSystems[0]
+	return gs.HiLoCount - hiLo.runningCount(gs.PlayerCards) - hiLo.runningCount(gs.DealerCards)
+}
+
+func indexPlayName(play indexPlay) string {
+	return "hard " + strconv.Itoa(play.Total) + " vs " + upcardRank(play.Upcard) + " at " + strconv.FormatFloat(play.Index, 'f', 0, 64) + "+"
+}
+
+// upcardRank names a card index, tens stand for every ten-valued card
+func upcardRank(index int) string {
+	if index == 0 {
+		return ace
+	}
+	return strconv.Itoa(index + 1)
+}
+
+// cardOfIndex is the inverse of cardIndex without a suit, tens come back as a "10"
+func cardOfIndex(index int) card {
+	if index == 0 {
+		return card{Rank: ace, Value: 11}
+	}
+	return card{Rank: upcardRank(index), Value: index + 1}
+}
+
+// ------------------- Deviation Charts -----------------------
+
+// printIndexPlays lists the Hi-Lo index plays. Insurance and the 10-10 splits of the
+// Illustrious 18 are missing, this table offers neither.
+func printIndexPlays(w io.Writer) {
+	_, _ = fmt.Fprintln(w, "Hi-Lo index plays, at or above the true count / below it:")
+	for _, play := range indexPlays {
+		below := "basic"
+		if play.Below != 0 {
+			below = string(actionLetters[play.Below])
+		}
+		_, _ = fmt.Fprintf(w, "  %-14s %-20s %c / %s\n", play.Set, indexPlayName(play), actionLetters[play.AtOrAbove], below)
+	}
+}
+
+// printCompositionExceptions lists the two-card hands whose own cards call for another
+// play than the typical hand of their total
+func printCompositionExceptions(w io.Writer, key strategyKey, table *strategyTable) {
+	_, _ = fmt.Fprintln(w, "Composition-dependent exceptions for two-card hands:")
+	found := false
+	for first := 1; first <= 9; first++ {
+		for second := first; second <= 9; second++ {
+			total := first + second + 2
+			for upcard := range 10 {
+				v := handView{
+					PlayerCards: []card{cardOfIndex(first), cardOfIndex(second)},
+					Upcard:      cardOfIndex(upcard),
+					PlayerTotal: total,
+					NumberDecks: key.NumberDecks,
+					HitOnSoft17: key.HitOnSoft17,
+					CanDouble:   key.CanDouble,
+				}
+				totalAction := table.Hard[total][upcard].bestAction(key.CanDouble)
+				cardsAction := compositionStrategy(v)
+				if totalAction == cardsAction {
+					continue
+				}
+				found = true
+				_, _ = fmt.Fprintf(w, "  hard %-2d (%s-%s) vs %-2s: %c instead of %c\n", total,
+					upcardRank(first), upcardRank(second), upcardRank(upcard),
+					actionLetters[cardsAction], actionLetters[totalAction])
+			}
+		}
+	}
+	if !found {
+		_, _ = fmt.Fprintln(w, "  none")
+	}
+}
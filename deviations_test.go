@@ -0,0 +1,58 @@
+package main
+
+import (
+	"testing"
+)
+
+// TestHiLoCountFollowsShoe plays rounds through reshuffles and checks the count kept while
+// dealing against the count of the shoe dealt again from RandomSeed
+func TestHiLoCountFollowsShoe(t *testing.T) {
+	hiLo := builtinCountSystems[0]
+	gs := gameState{NumberDecks: 2, Payout: 15, PlayerMoney: 60000, ReshuffleThreshold: reshuffleThreshold(2, 75)}
+	gs.DrawStack, gs.RandomSeed = newDeck(gs.NumberDecks, make([]card, 0, 104), 3)
+	check := func(round int) {
+		t.Helper()
+		want := hiLo.runningCount(dealtCards(gs))
+		if gs.HiLoCount != want {
+			t.Fatalf("round %d, %d cards dealt: HiLoCount %d, the shoe counts %d", round, gs.CardsDealt, gs.HiLoCount, want)
+		}
+	}
+	for round := range 200 {
+		gs = reshuffleIfNeeded(gs, uint32(round+1))
+		check(round)
+		gs = dealRound(placeBet(gs, 10))
+		check(round)
+		if gs.Phase == phasePlay {
+			before := countBeforeHand(gs)
+			dealt := dealtCards(gs)
+			want := hiLo.runningCount(dealt[:len(dealt)-4])
+			if before != want {
+				t.Fatalf("round %d: count before the hand %d, want %d", round, before, want)
+			}
+			if gs.PlayerTotal < 12 {
+				gs = playerHit(gs, normalLoose)
+				check(round)
+			}
+		}
+		if gs.Phase == phasePlay {
+			gs = dealerPlay(gs, normalWin, normalLoose, normalDraw)
+			check(round)
+		}
+	}
+}
+
+func TestRestoredSaveKeepsHiLoCount(t *testing.T) {
+	gs := midHandGame(t)
+	code, err := gs.encodeWithChecksum()
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, _, err := decodeGameState(inspectNoTimestamp + singleSpaceString + code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.HiLoCount != gs.HiLoCount || countBeforeHand(restored) != countBeforeHand(gs) {
+		t.Errorf("restored count %d, before the hand %d; played %d, %d",
+			restored.HiLoCount, countBeforeHand(restored), gs.HiLoCount, countBeforeHand(gs))
+	}
+}
@@ -0,0 +1,409 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	tea "github.com/charmbracelet/bubbletea"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ------------------- Drill Model ----------------------------
+
+func newDrillModel(back blackjackModel) drillModel {
+	history, err := loadDrillHistory(store.path(drillFile))
+	if err != nil {
+		log.Println(err)
+	}
+	return drillModel{
+		Back:         back,
+		UiText:       back.UiText,
+		Systems:      countSystemsWithCustom(),
+		History:      history,
+		Pace:         drillPaces[len(drillPaces)/2],
+		CardCount:    drillCardCounts[len(drillCardCounts)-1],
+		WindowWidth:  back.UiState.WindowWidth,
+		WindowHeight: back.UiState.WindowHeight,
+	}
+}
+
+func (m drillModel) Init() tea.Cmd {
+	return tea.WindowSize()
+}
+
+func (m drillModel) View() string {
+
+	poolIndex := getPoolIndex(m.WindowWidth, m.WindowHeight)
+	b := bufferPools[poolIndex].Get().([]byte)[:0]
+	defer bufferPools[poolIndex].Put(b)
+
+	b = m.wrapAndPad(b, m.UiText.DrillTitle+" ("+m.Systems[m.System].Name+")")
+	b = append(b, newlineRune)
+	b = append(b, newlineRune)
+
+	switch m.Stage {
+
+	case drillStageSetup:
+		b = m.wrapAndPad(b, m.UiText.PromptConfirmConfig)
+		b = append(b, newlineRune)
+		b = append(b, newlineRune)
+		b = m.wrapAndPad(b, renderOptions(m.options(), m.Cursor))
+		b = append(b, newlineRune)
+		b = append(b, newlineRune)
+		for _, line := range drillHistorySummary(m.UiText.DrillHistory, m.History, time.Now()) {
+			b = m.wrapAndPad(b, line)
+			b = append(b, newlineRune)
+		}
+
+	case drillStageFlash:
+		c := m.Cards[m.Shown-1]
+		b = m.wrapAndPad(b, strconv.Itoa(m.Shown)+"/"+strconv.Itoa(len(m.Cards)))
+		b = append(b, newlineRune)
+		b = append(b, newlineRune)
+		b = m.wrapAndPad(b, bjCards.CardCodes[rankToIndex(c.Rank)][suitToIndex(c.Suit)])
+		b = append(b, newlineRune)
+		b = append(b, newlineRune)
+
+	case drillStageAnswer:
+		b = m.wrapAndPad(b, m.UiText.DrillQuestion)
+		b = append(b, newlineRune)
+		b = m.wrapAndPad(b, optionCursorPrefix+m.Input)
+		b = append(b, newlineRune)
+		b = append(b, newlineRune)
+		b = m.wrapAndPad(b, m.UiText.CountInputPrompt)
+		b = append(b, newlineRune)
+		b = append(b, newlineRune)
+
+	case drillStageResult:
+		b = m.wrapAndPad(b, m.Result)
+		b = append(b, newlineRune)
+		b = append(b, newlineRune)
+		b = m.wrapAndPad(b, m.UiText.CountContinue)
+		b = append(b, newlineRune)
+		b = append(b, newlineRune)
+	}
+
+	b = m.verticalPad(b)
+	return string(b)
+}
+
+func (m drillModel) wrapAndPad(dst []byte, content string) []byte {
+	return wrapAndPadWMToBuffer(dst, content, m.WindowWidth, 2)
+}
+
+func (m drillModel) verticalPad(dst []byte) []byte {
+	return verticalPaddingToBuffer(dst, m.WindowWidth, m.WindowHeight)
+}
+
+func (m drillModel) options() []string {
+	return []string{
+		m.UiText.DrillStart,
+		m.UiText.DrillPace + strconv.Itoa(int(m.Pace.Milliseconds())) + " ms",
+		m.UiText.DrillCards + strconv.Itoa(m.CardCount),
+		m.UiText.DrillSystem + m.Systems[m.System].Name,
+	}
+}
+
+func (m drillModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+
+	var cmd tea.Cmd
+
+	switch message := msg.(type) {
+
+	case tea.WindowSizeMsg:
+		m.WindowWidth, m.WindowHeight, m.hideCursorPending, cmd = windowSize(m.WindowWidth, m.WindowHeight, m.hideCursorPending, message)
+		return m, cmd
+
+	case tea.KeyMsg:
+		return keyPressDrill(m, message)
+
+	case customHideCursorMsg:
+		m.hideCursorPending = false
+		return m, tea.HideCursor
+
+	case customDrillTickMsg:
+		if m.Stage != drillStageFlash || message.generation != m.Generation {
+			return m, nil // a tick of an aborted drill
+		}
+		if m.Shown == len(m.Cards) {
+			m.Stage = drillStageAnswer
+			return m, nil
+		}
+		m.Shown++
+		return m, m.tick()
+
+	default:
+		return m, nil
+	}
+}
+
+// tick schedules the next card the same way windowSize schedules customHideCursorMsg
+func (m drillModel) tick() tea.Cmd {
+	generation := m.Generation
+	return tea.Tick(m.Pace, func(t time.Time) tea.Msg { return customDrillTickMsg{generation: generation} })
+}
+
+func keyPressDrill(m drillModel, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+
+	case tea.KeyBackspace:
+		switch m.Stage {
+		case drillStageSetup:
+			m.Back.UiState.WindowWidth, m.Back.UiState.WindowHeight = m.WindowWidth, m.WindowHeight
+			return m.Back, m.Back.Init()
+		case drillStageAnswer:
+			if m.Input != emptyString {
+				m.Input = m.Input[:len(m.Input)-1]
+			}
+			return m, nil
+		default:
+			m.Stage = drillStageSetup
+			m.Generation++
+			return m, nil
+		}
+
+	case tea.KeyUp:
+		if m.Stage == drillStageSetup && m.Cursor >= 1 {
+			m.Cursor--
+		}
+		return m, nil
+
+	case tea.KeyDown:
+		if m.Stage == drillStageSetup && m.Cursor <= len(m.options())-2 {
+			m.Cursor++
+		}
+		return m, nil
+
+	case tea.KeyRunes:
+		if m.Stage == drillStageAnswer {
+			for _, r := range msg.Runes {
+				if (r >= '0' && r <= '9') || r == '-' {
+					m.Input += string(r)
+				}
+			}
+		}
+		return m, nil
+
+	case tea.KeyEnter:
+		switch m.Stage {
+		case drillStageSetup:
+			return handleDrillSetup(m)
+		case drillStageAnswer:
+			return answerDrill(m), nil
+		case drillStageResult:
+			m.Stage = drillStageSetup
+			return m, nil
+		}
+		return m, nil
+
+	default:
+		return m, nil
+	}
+}
+
+func handleDrillSetup(m drillModel) (tea.Model, tea.Cmd) {
+	switch m.Cursor {
+	case 0:
+		shoe, _ := newDeck(1, make([]card, 0, 52))
+		m.Cards = shoe[:m.CardCount]
+		m.Shown = 1
+		m.Input = emptyString
+		m.Generation++
+		m.Started = time.Now()
+		m.Stage = drillStageFlash
+		return m, m.tick()
+	case 1:
+		m.Pace = drillPaces[(indexOf(drillPaces, m.Pace)+1)%len(drillPaces)]
+	case 2:
+		m.CardCount = drillCardCounts[(indexOf(drillCardCounts, m.CardCount)+1)%len(drillCardCounts)]
+	case 3:
+		m.System = (m.System + 1) % len(m.Systems)
+	}
+	return m, nil
+}
+
+func indexOf[T comparable](values []T, value T) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
+
+// answerDrill grades the answer, the grade only reaches A at a fast pace
+func answerDrill(m drillModel) drillModel {
+	answer, err := strconv.Atoi(m.Input)
+	if err != nil {
+		return m
+	}
+	system := m.Systems[m.System]
+	count := system.runningCount(m.Cards)
+	missedBy := answer - count
+	elapsed := time.Since(m.Started)
+
+	var grade string
+	switch {
+	case missedBy == 0 && m.Pace <= 400*time.Millisecond:
+		grade = "A"
+	case missedBy == 0 && m.Pace <= 700*time.Millisecond:
+		grade = "B"
+	case missedBy == 0:
+		grade = "C"
+	case missedBy == 1 || missedBy == -1:
+		grade = "D"
+	default:
+		grade = "F"
+	}
+
+	result := drillResult{
+		Time:     time.Now().Truncate(time.Second),
+		System:   system.Name,
+		Cards:    len(m.Cards),
+		Pace:     m.Pace,
+		Elapsed:  elapsed,
+		MissedBy: missedBy,
+		Grade:    grade,
+	}
+	err = appendDrillResult(store.path(drillFile), result)
+	if err != nil {
+		log.Println(err)
+	}
+	m.History = append(m.History, result)
+
+	m.Result = m.UiText.DrillGrade + grade + " - " + m.UiText.CountRunning + strconv.Itoa(count) +
+		", " + m.UiText.DrillAnswer + strconv.Itoa(answer) + ", " + elapsed.Round(100*time.Millisecond).String()
+	m.Stage = drillStageResult
+	return m
+}
+
+// ------------------- Drill History --------------------------
+
+// Format per line: "timestamp system|cards|pace ms|elapsed ms|missed by|grade",
+// the name of an own system may hold a | itself, so the fields are read from the right
+func appendDrillResult(filename string, result drillResult) error {
+	file, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("error opening %s: %w", filename, err)
+	}
+	line := result.Time.Format(drillTimeLayout) + singleSpaceString + strings.Join([]string{
+		result.System,
+		strconv.Itoa(result.Cards),
+		strconv.FormatInt(result.Pace.Milliseconds(), 10),
+		strconv.FormatInt(result.Elapsed.Milliseconds(), 10),
+		strconv.Itoa(result.MissedBy),
+		result.Grade,
+	}, "|") + newlineString
+	_, err = file.WriteString(line)
+	closeErr := file.Close()
+	if err != nil {
+		return fmt.Errorf("error writing %s: %w", filename, err)
+	}
+	if closeErr != nil {
+		return fmt.Errorf("error closing %s: %w", filename, closeErr)
+	}
+	return nil
+}
+
+func loadDrillHistory(filename string) ([]drillResult, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error opening %s: %w", filename, err)
+	}
+	defer func() {
+		closeErr := file.Close()
+		if closeErr != nil {
+			log.Printf("error closing %s: %v", filename, closeErr)
+		}
+	}()
+
+	var history []drillResult
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		result, err := parseDrillResult(strings.TrimSpace(scanner.Text()))
+		if err != nil {
+			log.Printf("skipping drill history line: %v", err)
+			continue
+		}
+		history = append(history, result)
+	}
+	err = scanner.Err()
+	if err != nil {
+		return history, fmt.Errorf("error scanning %s: %w", filename, err)
+	}
+	return history, nil
+}
+
+func parseDrillResult(line string) (drillResult, error) {
+	timestamp, rest, ok := strings.Cut(line, singleSpaceString)
+	fields := strings.Split(rest, "|")
+	if !ok || len(fields) < 6 {
+		return drillResult{}, fmt.Errorf("invalid format: %q", line)
+	}
+	system := strings.Join(fields[:len(fields)-5], "|")
+	fields = fields[len(fields)-6:]
+	parsedTime, err := time.ParseInLocation(drillTimeLayout, timestamp, time.Local)
+	if err != nil {
+		return drillResult{}, fmt.Errorf("invalid timestamp: %w", err)
+	}
+	var numbers [4]int
+	for i, field := range fields[1:5] {
+		numbers[i], err = strconv.Atoi(field)
+		if err != nil {
+			return drillResult{}, fmt.Errorf("invalid number %q: %w", field, err)
+		}
+	}
+	return drillResult{
+		Time:     parsedTime,
+		System:   system,
+		Cards:    numbers[0],
+		Pace:     time.Duration(numbers[1]) * time.Millisecond,
+		Elapsed:  time.Duration(numbers[2]) * time.Millisecond,
+		MissedBy: numbers[3],
+		Grade:    fields[5],
+	}, nil
+}
+
+// drillHistorySummary shows one line per ISO week for the last weeks with drills
+func drillHistorySummary(title string, history []drillResult, now time.Time) []string {
+	if len(history) == 0 {
+		return nil
+	}
+	lines := make([]string, 0, drillSummaryWeeks+1)
+	for week := drillSummaryWeeks - 1; week >= 0; week-- {
+		year, number := now.AddDate(0, 0, -7*week).ISOWeek()
+		drills, exact := 0, 0
+		var fastest time.Duration
+		for _, result := range history {
+			resultYear, resultNumber := result.Time.ISOWeek()
+			if resultYear != year || resultNumber != number {
+				continue
+			}
+			drills++
+			if result.MissedBy == 0 {
+				exact++
+				if fastest == 0 || result.Pace < fastest {
+					fastest = result.Pace
+				}
+			}
+		}
+		if drills == 0 {
+			continue
+		}
+		line := fmt.Sprintf("%d-W%02d: %d x, %d %% exact", year, number, drills, 100*exact/drills)
+		if fastest > 0 {
+			line += ", " + strconv.Itoa(int(fastest.Milliseconds())) + " ms"
+		}
+		lines = append(lines, line)
+	}
+	return append([]string{title}, lines...)
+}
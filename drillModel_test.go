@@ -0,0 +1,70 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDrillHistoryKeepsSystemNames(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), drillFile)
+	now := time.Date(2024, 5, 1, 20, 0, 0, 0, time.Local)
+	want := []drillResult{
+		{Time: now, System: "Hi-Lo", Grade: "A", Cards: 52, Pace: 400 * time.Millisecond, Elapsed: 21 * time.Second},
+		{Time: now, System: "KO | fast|", Grade: "C", Cards: 26, MissedBy: -2, Pace: 250 * time.Millisecond, Elapsed: 7 * time.Second},
+	}
+	for _, result := range want {
+		err := appendDrillResult(filename, result)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	file, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = file.WriteString("not a drill\n2024-05-01 20:00:00 Hi-Lo|52|400\n")
+	closeErr := file.Close()
+	if err != nil || closeErr != nil {
+		t.Fatal(err, closeErr)
+	}
+
+	got, err := loadDrillHistory(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d drills, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if !got[i].Time.Equal(want[i].Time) || got[i].System != want[i].System || got[i].Grade != want[i].Grade ||
+			got[i].Cards != want[i].Cards || got[i].MissedBy != want[i].MissedBy || got[i].Pace != want[i].Pace ||
+			got[i].Elapsed != want[i].Elapsed {
+			t.Errorf("drill %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestDrillHistorySummary(t *testing.T) {
+	now := time.Date(2024, 5, 15, 12, 0, 0, 0, time.Local) // a Wednesday of week 20
+	history := []drillResult{
+		{Time: now.AddDate(0, 0, -60), Pace: 100 * time.Millisecond}, // too old to show
+		{Time: now.AddDate(0, 0, -7), Pace: 500 * time.Millisecond},
+		{Time: now.AddDate(0, 0, -7), Pace: 300 * time.Millisecond, MissedBy: 1},
+		{Time: now, Pace: 450 * time.Millisecond},
+	}
+	got := drillHistorySummary("Drills", history, now)
+	want := []string{"Drills", "2024-W19: 2 x, 50 % exact, 500 ms", "2024-W20: 1 x, 100 % exact, 450 ms"}
+	if len(got) != len(want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("line %d: got %q, want %q", i, got[i], want[i])
+		}
+	}
+	if drillHistorySummary("Drills", nil, now) != nil {
+		t.Error("a summary without drills")
+	}
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ------------------- Shoe Composition -----------------------
+
+// index 0 holds the aces, index 1-8 the twos to nines and index 9 every ten-valued card
+func cardIndex(c card) int {
+	if c.Rank == ace {
+		return 0
+	}
+	return c.Value - 1
+}
+
+func fullShoe(numberDecks uint8) shoeCounts {
+	var shoe shoeCounts
+	for i := range shoe {
+		shoe[i] = 4 * int(numberDecks)
+	}
+	shoe[9] = 16 * int(numberDecks)
+	return shoe
+}
+
+func countShoe(cards []card) shoeCounts {
+	var shoe shoeCounts
+	for _, c := range cards {
+		shoe[cardIndex(c)]++
+	}
+	return shoe
+}
+
+func (s shoeCounts) total() int {
+	n := 0
+	for _, count := range s {
+		n += count
+	}
+	return n
+}
+
+// hard totals count aces as 1, the hand value uses one ace as 11 when it doesn't bust
+func handValue(hardTotal int, hasAce bool) int {
+	if hasAce && hardTotal+10 <= 21 {
+		return hardTotal + 10
+	}
+	return hardTotal
+}
+
+// ------------------- Dealer Probabilities -------------------
+
+// dealerOutcomes returns the probabilities of the dealer finishing on 17, 18, 19, 20, 21
+// or busting. The hole card is drawn from the unseen cards on the condition that the
+// dealer has no natural, because the round would already be over otherwise.
+func dealerOutcomes(shoe shoeCounts, upcard int, hitOnSoft17 bool) [6]float64 {
+	var dist [6]float64
+	n := shoe.total()
+	excluded := -1
+	switch upcard {
+	case 0:
+		excluded = 9
+	case 9:
+		excluded = 0
+	}
+	holeCards := n
+	if excluded >= 0 {
+		holeCards -= shoe[excluded]
+	}
+	if holeCards <= 0 {
+		return dist
+	}
+	for r := range shoe {
+		if r == excluded || shoe[r] == 0 {
+			continue
+		}
+		p := float64(shoe[r]) / float64(holeCards)
+		shoe[r]--
+		dealerDraw(&shoe, n-1, upcard+r+2, upcard == 0 || r == 0, p, hitOnSoft17, &dist)
+		shoe[r]++
+	}
+	return dist
+}
+
+// follows the loop in dealerPlay: draw to 16 and on soft 17 when the dealer hits soft 17
+func dealerDraw(shoe *shoeCounts, n, hardTotal int, hasAce bool, p float64, hitOnSoft17 bool, dist *[6]float64) {
+	value := handValue(hardTotal, hasAce)
+	soft := value != hardTotal
+	switch {
+	case value >= 22:
+		dist[5] += p
+		return
+	case value >= 18 || (value == 17 && !(soft && hitOnSoft17)):
+		dist[value-17] += p
+		return
+	case n == 0:
+		return // the shoe is empty, this never happens with a sane penetration
+	}
+	for r := range shoe {
+		if shoe[r] == 0 {
+			continue
+		}
+		q := p * float64(shoe[r]) / float64(n)
+		shoe[r]--
+		dealerDraw(shoe, n-1, hardTotal+r+1, hasAce || r == 0, q, hitOnSoft17, dist)
+		shoe[r]++
+	}
+}
+
+// ------------------- Player Expected Values -----------------
+
+func standEV(value int, dist [6]float64) float64 {
+	if value >= 22 {
+		return -1
+	}
+	ev := dist[5]
+	for i := 0; i < 5; i++ {
+		switch dealerValue := 17 + i; {
+		case value > dealerValue:
+			ev += dist[i]
+		case value < dealerValue:
+			ev -= dist[i]
+		}
+	}
+	return ev
+}
+
+// Without exact the dealer probabilities of the starting shoe are reused after every
+// player hit, which is the usual approximation for strategy tables and a lot faster.
+func newEVEngine(shoe shoeCounts, upcard int, hitOnSoft17, canDouble, exact bool) *evEngine {
+	e := &evEngine{
+		playerCache: make(map[shoeCounts]actionEVs),
+		upcard:      upcard,
+		hitOnSoft17: hitOnSoft17,
+		canDouble:   canDouble,
+		exact:       exact,
+	}
+	if exact {
+		e.dealerCache = make(map[shoeCounts][6]float64)
+	} else {
+		e.dist = dealerOutcomes(shoe, upcard, hitOnSoft17)
+	}
+	return e
+}
+
+func (e *evEngine) dealerDist(shoe shoeCounts) [6]float64 {
+	if !e.exact {
+		return e.dist
+	}
+	dist, ok := e.dealerCache[shoe]
+	if !ok {
+		dist = dealerOutcomes(shoe, e.upcard, e.hitOnSoft17)
+		e.dealerCache[shoe] = dist
+	}
+	return dist
+}
+
+// evaluate returns the expected value per unit bet of every option for a hand with the
+// given hard total, drawing from shoe. The shoe already determines which cards were
+// drawn since the first call, so it is the only memo key needed.
+// Surrender and double stay available after hitting, like in handleSelection.
+func (e *evEngine) evaluate(shoe shoeCounts, hardTotal int, hasAce bool) actionEVs {
+	evs, ok := e.playerCache[shoe]
+	if ok {
+		return evs
+	}
+
+	evs[actionStand] = standEV(handValue(hardTotal, hasAce), e.dealerDist(shoe))
+	evs[actionSurrender] = -0.5
+	n := shoe.total()
+	for r := range shoe {
+		if shoe[r] == 0 {
+			continue
+		}
+		p := float64(shoe[r]) / float64(n)
+		next := shoe
+		next[r]--
+		nextTotal := hardTotal + r + 1
+		nextAce := hasAce || r == 0
+		if nextTotal >= 22 {
+			evs[actionHit] -= p
+			evs[actionDouble] -= 2 * p
+			continue
+		}
+		evs[actionHit] += p * e.evaluate(next, nextTotal, nextAce).best(e.canDouble)
+		evs[actionDouble] += 2 * p * standEV(handValue(nextTotal, nextAce), e.dealerDist(next))
+	}
+
+	e.playerCache[shoe] = evs
+	return evs
+}
+
+// bestAction prefers stand, hit, double and surrender in that order on equal values
+func (evs actionEVs) bestAction(doubleAllowed bool) int {
+	best := actionStand
+	for _, action := range [3]int{actionHit, actionDouble, actionSurrender} {
+		if action == actionDouble && !doubleAllowed {
+			continue
+		}
+		if evs[action] > evs[best] {
+			best = action
+		}
+	}
+	return best
+}
+
+func (evs actionEVs) best(doubleAllowed bool) float64 {
+	return evs[evs.bestAction(doubleAllowed)]
+}
+
+// ------------------- Current Hand Analysis ------------------
+
+// unseenCards counts what the player can't see: the rest of the DrawStack and the
+// hole card while the dealer hand is hidden. Reading the hole card itself would be cheating.
+func unseenCards(gs gameState) shoeCounts {
+	shoe := countShoe(gs.DrawStack)
+	if !gs.ShowDealerHand && len(gs.DealerCards) > 1 {
+		shoe[cardIndex(gs.DealerCards[1])]++
+	}
+	return shoe
+}
+
+// analyzeHand computes the exact expected values of the current hand in the background,
+// recalculating the dealer probabilities after every card the player could draw.
+// The shoe is counted here, so the command never touches the DrawStack of the model.
+func analyzeHand(gs gameState) tea.Cmd {
+	shoe := unseenCards(gs)
+	upcard := cardIndex(gs.DealerCards[0])
+	hitOnSoft17 := gs.HitOnSoft17
+	doubleAllowed := canDouble(gs)
+	hardTotal := 0
+	hasAce := false
+	for _, c := range gs.PlayerCards {
+		hardTotal += cardIndex(c) + 1
+		hasAce = hasAce || c.Rank == ace
+	}
+	hand := currentHand(gs)
+	return func() tea.Msg {
+		engine := newEVEngine(shoe, upcard, hitOnSoft17, doubleAllowed, true)
+		return customAnalysisMsg{evs: engine.evaluate(shoe, hardTotal, hasAce), hand: hand}
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ------------------- Save Inspector -------------------------
+
+// runInspect decodes save lines step by step and prints every field, so a save the load
+// screen rejects shows which step failed. The lines come from the arguments, a slot of
+// save.txt or stdin, one per line. It exits with 1 when any line doesn't load.
+func runInspect(args []string) int {
+	flags := flag.NewFlagSet(commandInspect, flag.ContinueOnError)
+	slotName := flags.String("slot", emptyString, "inspect the slot of this name in "+saveFile)
+	err := flags.Parse(args)
+	if err != nil {
+		return 2
+	}
+
+	var lines []string
+	switch {
+	case *slotName != emptyString:
+		slots := readSlots()
+		i := slices.IndexFunc(slots, func(s saveSlot) bool { return s.Name == *slotName })
+		if i < 0 {
+			_, _ = fmt.Fprintln(os.Stderr, "inspect: no slot named "+strconv.Quote(*slotName)+" in "+store.path(saveFile))
+			return 2
+		}
+		lines = append(lines, slots[i].line())
+	case flags.NArg() > 0:
+		lines = append(lines, strings.Join(flags.Args(), singleSpaceString)) // the line unquoted
+	default:
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			if strings.TrimSpace(scanner.Text()) != emptyString {
+				lines = append(lines, scanner.Text())
+			}
+		}
+		if scanner.Err() != nil {
+			_, _ = fmt.Fprintln(os.Stderr, "inspect: "+scanner.Err().Error())
+			return 2
+		}
+	}
+
+	exitCode := 0
+	for i, line := range lines {
+		if i > 0 {
+			_, _ = fmt.Fprintln(os.Stdout)
+		}
+		err = inspectSave(os.Stdout, line)
+		if err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, "inspect: "+err.Error())
+			exitCode = 1
+		}
+	}
+	return exitCode
+}
+
+// inspectSave runs the steps of decodeGameState one by one. The fields are printed as saved,
+// before validateSavable repairs them, and even when the checksum or the MAC fails, marked
+// as unverified, as they show what was changed. The error is the step that keeps the save from loading.
+func inspectSave(w io.Writer, line string) error {
+	field := func(label, value string) {
+		_, _ = fmt.Fprintf(w, "%-22s%s\n", label+":", value)
+	}
+	var checkErr error
+	failed := func(label string, err error) error {
+		field(label, "FAILED: "+err.Error())
+		if checkErr != nil {
+			return checkErr // decodeGameState stops at the check already
+		}
+		return fmt.Errorf("%s: %w", strings.ToLower(label), err)
+	}
+
+	name, saveString := inspectInput(line)
+	if name != emptyString {
+		field("Slot", name)
+	}
+	timestamp, base45String, check, err := parseSaveString(saveString)
+	if err != nil {
+		return failed("Format", err)
+	}
+	if timestamp != inspectNoTimestamp {
+		field("Saved at", timestamp)
+	}
+	payload, err := decodeBase45(base45String)
+	if err != nil {
+		return failed("Base45", err)
+	}
+	field("Payload", fmt.Sprintf("%d bytes: % x", len(payload), payload))
+
+	kind := "checksum"
+	if len(check) == saveMACLength {
+		kind = "MAC"
+	}
+	err = verifySaveCheck(payload, check)
+	if err != nil {
+		checkErr = failed("Check ("+kind+")", err)
+	} else {
+		field("Check ("+kind+")", "ok")
+	}
+
+	buf, err := migrateSave(payload)
+	if err != nil {
+		return failed("Version", err)
+	}
+	version := 0
+	if len(payload) != saveLayoutLengths[0] {
+		version = int(payload[0])
+	}
+	if version < saveVersion {
+		field("Version", strconv.Itoa(version)+", migrated to "+strconv.Itoa(saveVersion))
+	} else {
+		field("Version", strconv.Itoa(version))
+	}
+
+	s := unpackSave(buf)
+	unverified := emptyString
+	if checkErr != nil {
+		unverified = " (unverified)"
+	}
+	yesNo := map[bool]string{true: "yes", false: "no"}
+	penetration := penetrationOf(gameState{NumberDecks: s.NumberDecks, ReshuffleThreshold: s.ReshuffleThreshold})
+	field("Seed", strconv.FormatUint(uint64(s.RandomSeed), 10)+unverified)
+	field("Money", strconv.Itoa(int(s.playerMoney))+unverified)
+	field("Decks", strconv.Itoa(int(s.NumberDecks))+unverified)
+	field("Reshuffle threshold", strconv.Itoa(int(s.ReshuffleThreshold))+" cards left ("+strconv.Itoa(int(penetration))+" % penetration)"+unverified)
+	field("Cards dealt", strconv.Itoa(int(s.CardsDealt))+" of "+strconv.Itoa(int(s.NumberDecks)*52)+unverified)
+	field("Dealer hits soft 17", yesNo[s.HitOnSoft17]+unverified)
+	field("Payout", strconv.Itoa(int(s.Payout))+" ("+payoutLabel(s.Payout)+")"+unverified)
+	field("Reshuffle pending", yesNo[s.NeedReshuffle]+unverified)
+	if s.PlayerCardCount == 0 {
+		field("Hand in progress", "none"+unverified)
+	} else {
+		field("Hand in progress", "bet "+strconv.Itoa(int(s.Bet))+", "+strconv.Itoa(int(s.PlayerCardCount))+
+			" player cards, turn "+strconv.Itoa(int(s.Turn))+unverified)
+	}
+
+	s, repairs, err := validateSavable(s)
+	if err == nil {
+		_, err = restoreGameState(s)
+	}
+	if err != nil {
+		return failed("Validation", err)
+	}
+	for _, repair := range repairs {
+		field("Repaired", repair)
+	}
+	field("Validation", "ok")
+	return checkErr
+}
+
+// inspectInput accepts what the load screen accepts: a save line, a line of save.txt with
+// its slot name, a bare code or the text of a scanned QR code
+func inspectInput(line string) (name, saveString string) {
+	line = strings.TrimSpace(line)
+	code, scanned := saveCodeFromQR(line)
+	if scanned {
+		return emptyString, inspectNoTimestamp + singleSpaceString + code
+	}
+	save, name, _ := strings.Cut(line, tabString)
+	if !strings.Contains(save, singleSpaceString) {
+		return name, inspectNoTimestamp + singleSpaceString + save
+	}
+	return name, save
+}
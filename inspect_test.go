@@ -0,0 +1,33 @@
+package main
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestInspectSaveReportsFailingStep(t *testing.T) {
+	gs := gameState{RandomSeed: 7, NumberDecks: 2, Payout: 15, PlayerMoney: startingMoney, ReshuffleThreshold: reshuffleThreshold(2, 75)}
+	code, err := gs.encodeWithChecksum()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = inspectSave(io.Discard, code)
+	if err != nil {
+		t.Fatalf("a valid code fails: %v", err)
+	}
+
+	base45String, check, _ := strings.Cut(code, "=")
+	tampered := "0" + base45String[1:] + "=" + check
+	if tampered == code {
+		tampered = "1" + base45String[1:] + "=" + check
+	}
+	err = inspectSave(io.Discard, tampered)
+	if err == nil || !strings.HasPrefix(err.Error(), "check (checksum)") {
+		t.Errorf("a tampered code gives %v, want the checksum step", err)
+	}
+	err = inspectSave(io.Discard, "not a save")
+	if err == nil {
+		t.Error("text without a code passes")
+	}
+}
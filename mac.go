@@ -0,0 +1,128 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"strings"
+)
+
+// ------------------- Save MAC -------------------------------
+
+// In casual mode saves carry the 20-bit CRC of the payload, which catches typos but not edits.
+// In mac mode they carry a MAC keyed with the secret of this install instead, so a changed
+// PlayerMoney can't be signed again without secret.key. Both kinds are told apart by length.
+
+// saveMAC is HMAC-SHA256 of the payload truncated to 60 bits, written as three 20-bit base32 groups
+func saveMAC(key, payload []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	sum := binary.BigEndian.Uint64(mac.Sum(nil))
+	return encodeBase32From20Bits(uint32(sum>>44)&0xFFFFF) +
+		encodeBase32From20Bits(uint32(sum>>24)&0xFFFFF) +
+		encodeBase32From20Bits(uint32(sum>>4)&0xFFFFF)
+}
+
+// verifySaveCheck accepts a MAC of this install in every mode and a CRC only in casual mode
+func verifySaveCheck(payload []byte, check string) error {
+	switch len(check) {
+
+	case saveMACLength:
+		key, err := installSecret()
+		if err != nil {
+			return fmt.Errorf("%w: %v", errSaveUnverifiable, err)
+		}
+		if subtle.ConstantTimeCompare([]byte(strings.ToUpper(check)), []byte(saveMAC(key, payload))) != 1 {
+			return errSaveTampered
+		}
+		return nil
+
+	case 4:
+		if saveMode == saveModeMAC {
+			return errSaveUnsigned
+		}
+		decodedBase32, err := decodeBase32To20Bits(check)
+		if err != nil {
+			return fmt.Errorf("failed to decode checksum: %v", err)
+		}
+		expectedChecksum := crc32.ChecksumIEEE(payload) & 0xFFFFF
+		if decodedBase32 != expectedChecksum {
+			return fmt.Errorf("%w: expected %d, got %d", errSaveChecksum, expectedChecksum, decodedBase32)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("failed to decode checksum: expected 4 characters or a %d-character MAC, got %d", saveMACLength, len(check))
+	}
+}
+
+// loadInstallSecret reads the key of this install and creates it on first use. The file is
+// created exclusively, so two games started at once can't end up with different keys.
+func loadInstallSecret(filename string) ([]byte, error) {
+	content, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		key := make([]byte, secretLength)
+		_, err = rand.Read(key)
+		if err != nil {
+			return nil, fmt.Errorf("error generating %s: %w", filename, err)
+		}
+		err = createExclusive(filename, []byte(hex.EncodeToString(key)+"\n"))
+		if err == nil {
+			return key, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("error writing %s: %w", filename, err)
+		}
+		content, err = os.ReadFile(filename) // the other game was faster
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", filename, err)
+	}
+	key, err := hex.DecodeString(strings.TrimSpace(string(content)))
+	if err != nil || len(key) != secretLength {
+		return nil, fmt.Errorf("%s is damaged, saves signed with it can't be verified anymore", filename)
+	}
+	return key, nil
+}
+
+func createExclusive(filename string, data []byte) error {
+	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	if err == nil {
+		err = file.Sync()
+	}
+	closeErr := file.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return errors.Join(err, os.Remove(filename))
+	}
+	return nil
+}
+
+// loadErrorReason explains on configStepLoadFail why a save was rejected
+func loadErrorReason(text uiText, err error) string {
+	switch {
+	case errors.Is(err, errSaveTampered):
+		return text.LoadFailTampered
+	case errors.Is(err, errSaveUnsigned):
+		return text.LoadFailUnsigned
+	case errors.Is(err, errSaveUnverifiable):
+		return text.LoadFailUnverifiable + err.Error()
+	case errors.Is(err, errSaveChecksum):
+		return text.LoadFailChecksum
+	default:
+		return text.LoadFailInvalid + err.Error()
+	}
+}
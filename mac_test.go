@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// withSaveMode switches saveMode for one test
+func withSaveMode(t *testing.T, mode string) {
+	t.Helper()
+	before := saveMode
+	saveMode = mode
+	t.Cleanup(func() { saveMode = before })
+}
+
+func TestVerifySaveCheck(t *testing.T) {
+	key, err := installSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	gs := gameState{RandomSeed: 99, NumberDecks: 6, Payout: 15, PlayerMoney: 300, ReshuffleThreshold: 234}
+	payload := gs.savePayload()
+	mac := saveMAC(key, payload)
+	if len(mac) != saveMACLength {
+		t.Fatalf("the MAC has %d characters, want %d", len(mac), saveMACLength)
+	}
+	richer := gs
+	richer.PlayerMoney = 60000
+	otherKey := bytes.Repeat([]byte{1}, secretLength)
+	checksumCode, err := gs.encodeWithChecksum()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, checksum, _ := strings.Cut(checksumCode, equalSignString)
+
+	tests := []struct {
+		name    string
+		mode    string
+		payload []byte
+		check   string
+		want    error
+	}{
+		{"MAC", saveModeMAC, payload, mac, nil},
+		{"MAC in lowercase", saveModeMAC, payload, strings.ToLower(mac), nil},
+		{"MAC in casual mode", saveModeCasual, payload, mac, nil},
+		{"changed money", saveModeMAC, richer.savePayload(), mac, errSaveTampered},
+		{"changed money in casual mode", saveModeCasual, richer.savePayload(), mac, errSaveTampered},
+		{"MAC of another install", saveModeMAC, payload, saveMAC(otherKey, payload), errSaveTampered},
+		{"checksum in mac mode", saveModeMAC, payload, checksum, errSaveUnsigned},
+		{"checksum", saveModeCasual, payload, checksum, nil},
+		{"changed money with a checksum", saveModeCasual, richer.savePayload(), checksum, errSaveChecksum},
+	}
+	for _, test := range tests {
+		withSaveMode(t, test.mode)
+		err := verifySaveCheck(test.payload, test.check)
+		if !errors.Is(err, test.want) || (test.want == nil) != (err == nil) {
+			t.Errorf("%s: got %v, want %v", test.name, err, test.want)
+		}
+	}
+}
+
+func TestMACSaveLoadsOnlyWhenSigned(t *testing.T) {
+	withSaveMode(t, saveModeMAC)
+	key, err := installSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	gs := gameState{RandomSeed: 5, NumberDecks: 2, Payout: 14, PlayerMoney: 80, ReshuffleThreshold: 52}
+	loaded := decodeCode(t, gs.encodeWithMAC(key))
+	if savedFields(loaded) != savedFields(gs) {
+		t.Errorf("got %+v, want %+v", savedFields(loaded), savedFields(gs))
+	}
+	code, err := gs.encodeWithChecksum()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = decodeGameState(inspectNoTimestamp + singleSpaceString + code)
+	if !errors.Is(err, errSaveUnsigned) {
+		t.Errorf("an unsigned save in mac mode gives %v", err)
+	}
+}
+
+func TestInstallSecretIsKept(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), secretFile)
+	first, err := loadInstallSecret(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := loadInstallSecret(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first) != secretLength || !bytes.Equal(first, second) {
+		t.Errorf("the second start read another key")
+	}
+	info, err := os.Stat(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0600 {
+		t.Errorf("%s has mode %v, want 0600", secretFile, info.Mode().Perm())
+	}
+
+	err = os.WriteFile(filename, []byte("not hex\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = loadInstallSecret(filename)
+	if err == nil {
+		t.Error("a damaged key loads")
+	}
+}
+
+func TestRepairedMACSavePlaysToFirstBet(t *testing.T) {
+	withSaveMode(t, saveModeMAC)
+	resetStorage(t)
+	gs := gameState{RandomSeed: 11, NumberDecks: 1, Payout: 15, PlayerMoney: 200, ReshuffleThreshold: 52, CardsDealt: 30}
+	err := storeSlot("Player", gs, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, check, _ := strings.Cut(findSlot(t, readSlots(), "Player").Code, equalSignString)
+	if len(check) != saveMACLength {
+		t.Fatalf("the slot is stored with %q, want a MAC", check)
+	}
+
+	m := loadSlot(t, "Player")
+	if m.Game.Phase != phaseBet || m.Game.ReshuffleThreshold != 26 || m.Game.CardsDealt != 0 {
+		t.Fatalf("phase %v, threshold %d, %d cards dealt; want the repaired table on a fresh shoe",
+			m.Game.Phase, m.Game.ReshuffleThreshold, m.Game.CardsDealt)
+	}
+	model, _ := handleBetSelection(m, bet10)
+	m = model.(blackjackModel)
+	if m.Game.CardsDealt < 4 || m.Game.Bet != 10 {
+		t.Errorf("%d cards dealt for a bet of %d; want a round dealt for 10", m.Game.CardsDealt, m.Game.Bet)
+	}
+}
@@ -2,13 +2,17 @@ package main
 
 import (
 	"bytes"
+	"errors"
+	"flag"
 	"fmt"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/rivo/uniseg"
 	"log"
 	"os"
+	"os/signal"
 	"runtime/debug"
 	"strings"
+	"syscall"
 	"time"
 	"unicode"
 	"unicode/utf8"
@@ -18,20 +22,83 @@ import (
 
 func main() {
 	debug.SetGCPercent(10)
+	os.Exit(run(os.Args[1:]))
+}
 
-	logFile := setupLogging(debugFile)
-	defer func() {
-		err := logFile.Close()
-		if err != nil {
-			fmt.Print("Error while closing " + debugFile + ": " + err.Error())
+func run(args []string) int {
+	flags := flag.NewFlagSet(appName, flag.ContinueOnError)
+	dataDir := flags.String("data-dir", emptyString, "directory for saves, statistics and the log (default $"+dataDirEnv+" or the data directory of the platform)")
+	mode := flags.String("save-mode", saveModeCasual, "\""+saveModeCasual+"\" accepts checksum saves, \""+saveModeMAC+"\" signs saves with "+secretFile+" and rejects unsigned ones")
+	err := flags.Parse(args)
+	if err != nil {
+		return 2
+	}
+	if *mode != saveModeCasual && *mode != saveModeMAC {
+		fmt.Println("unknown save mode: " + *mode)
+		return 2
+	}
+	saveMode = *mode
+	args = flags.Args()
+	store, err = openStorage(*dataDir)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	if len(args) == 0 {
+		// only the game truncates debug.log, a command keeps its messages on stderr
+		// and leaves the log of the last game for a bug report
+		logPath := store.path(debugFile)
+		logFile := setupLogging(logPath)
+		defer func() {
+			closeErr := logFile.Close()
+			if closeErr != nil {
+				fmt.Print("Error while closing " + logPath + ": " + closeErr.Error())
+			}
+		}()
+	}
+	store.adoptLegacyFiles()
+
+	if len(args) > 0 {
+		switch args[0] {
+		case commandSimulate:
+			return runSimulate(args[1:])
+		case commandStrategy:
+			return runStrategy(args[1:])
+		case commandRuin:
+			return runRuin(args[1:])
+		case commandBot:
+			return runBot(args[1:])
+		case commandInspect:
+			return runInspect(args[1:])
+		default:
+			fmt.Println("unknown command: " + args[0])
+			fmt.Println("usage: go-blackjack-tui [-data-dir dir] [-save-mode casual|mac] [" + commandSimulate + " | " + commandStrategy + " | " + commandRuin + " | " + commandBot + " | " + commandInspect + "]")
+			return 2
 		}
-	}()
+	}
 
 	p := tea.NewProgram(
 		languageModel{Cursor: 0, Page: 0},
 		tea.WithFPS(120), tea.WithAltScreen(),
 	)
-	_, _ = p.Run()
+	// Bubble Tea handles SIGINT and SIGTERM, a closed terminal sends SIGHUP
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	go func() {
+		<-hangup
+		p.Quit()
+	}()
+	final, err := p.Run()
+	signal.Stop(hangup)
+	if err != nil && !errors.Is(err, tea.ErrInterrupted) {
+		log.Println(err)
+	}
+	m, ok := final.(blackjackModel)
+	if ok {
+		exitGame(m)
+	}
+	return 0
 }
 
 func setupLogging(file string) *os.File {
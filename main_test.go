@@ -0,0 +1,51 @@
+package main
+
+import (
+	"log"
+	"os"
+	"testing"
+)
+
+// TestMain points the data directory at a temporary one, so no test reads or writes the saves of the player
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", appName+"-test-")
+	if err != nil {
+		log.Fatal(err)
+	}
+	store = storage{Dir: dir}
+	code := m.Run()
+	err = os.RemoveAll(dir)
+	if err != nil {
+		log.Println(err)
+	}
+	os.Exit(code)
+}
+
+// resetStorage gives the test an empty data directory of its own and puts the shared one
+// back afterwards, so tests without a reset never write into a removed directory
+func resetStorage(t *testing.T) {
+	t.Helper()
+	before := store
+	store = storage{Dir: t.TempDir()}
+	t.Cleanup(func() { store = before })
+}
+
+func TestCommandKeepsDebugLog(t *testing.T) {
+	resetStorage(t)
+	logPath := store.path(debugFile)
+	err := os.WriteFile(logPath, []byte("log of the last game\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := run([]string{"-data-dir", store.Dir, commandInspect, "-slot", "nobody"})
+	if code != 2 {
+		t.Errorf("inspect of a missing slot exits with %d", code)
+	}
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "log of the last game\n" {
+		t.Errorf("the command left %q in %s", data, debugFile)
+	}
+}
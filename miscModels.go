@@ -13,7 +13,7 @@ import (
 
 func (m languageModel) Init() tea.Cmd {
 	loadLanguages := func() tea.Msg {
-		languages, err := loadLanguagesFromJSON(path)
+		languages, err := loadLanguagesFromJSON(store.resource(path))
 		if err != nil {
 			return customErrorMsg{err: err}
 		}
@@ -158,7 +158,7 @@ func keyPressLanguage(m languageModel, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		ui = finalizeUiStrings(ui)
 		model := blackjackModel{
 			Game:    gameState{Phase: phaseConfig, ConfigStep: configStepStartUp},
-			UiState: uiState{Cursor: 0, langLoadPage: m.Page, LoadPage: 0, firstTime: true},
+			UiState: uiState{Cursor: 0, langLoadPage: m.Page, LoadPage: 0},
 			UiText:  ui,
 		}
 		return model, model.Init()
@@ -169,27 +169,28 @@ func keyPressLanguage(m languageModel, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func loadUiStrings(language string) (uiText, error) {
-	file, err := os.Open(path)
+	filename := store.resource(path)
+	file, err := os.Open(filename)
 	if err != nil {
-		log.Println("failed to open " + path)
+		log.Println("failed to open " + filename)
 		return uiText{}, fmt.Errorf("%w", err)
 	}
 	defer func() {
 		closeErr := file.Close()
 		if closeErr != nil {
-			log.Println("Error while closing " + path + ": " + closeErr.Error())
+			log.Println("Error while closing " + filename + ": " + closeErr.Error())
 		}
 	}()
 
 	var langMap map[string]uiText
 	err = json.NewDecoder(file).Decode(&langMap)
 	if err != nil {
-		log.Println("failed to decode " + path)
+		log.Println("failed to decode " + filename)
 		return uiText{}, fmt.Errorf("%w", err)
 	}
 	uiStrings, ok := langMap[language]
 	if !ok {
-		log.Println("language '"+language+"' not found in", path)
+		log.Println("language '"+language+"' not found in", filename)
 		return uiText{}, fmt.Errorf("%v", ok)
 	}
 	return uiStrings, nil
@@ -289,6 +290,13 @@ func (m gameOverModel) View() string {
 	b = m.wrapAndPad(b, "GAME OVER - Thank you for playing")
 	b = append(b, newlineRune)
 	b = append(b, newlineRune)
+	if len(m.Stats) > 0 {
+		for _, line := range m.Stats {
+			b = m.wrapAndPad(b, line)
+			b = append(b, newlineRune)
+		}
+		b = append(b, newlineRune)
+	}
 	b = m.wrapAndPad(b, "Press Q to exit...")
 	b = append(b, newlineRune)
 	b = append(b, newlineRune)
@@ -0,0 +1,465 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ------------------- QR Code --------------------------------
+
+// The save code becomes a QR code in two segments: the base45 payload in alphanumeric mode and
+// "=check" in byte mode, as "=" is not in the alphanumeric set. The base45 alphabet of the
+// save code has lowercase letters, so the payload is mapped index by index onto
+// qrAlphanumeric and marked by a leading "0", a character the save alphabet doesn't have.
+
+// qrSaveText is the text a phone reads from the QR code of a save code
+func qrSaveText(code string) (alphanumeric, tail string) {
+	payload, check, _ := strings.Cut(code, equalSignString)
+	var sb strings.Builder
+	sb.WriteByte(qrAlphanumeric[0])
+	for i := 0; i < len(payload); i++ {
+		sb.WriteByte(qrAlphanumeric[strings.IndexByte(base45Chars, payload[i])])
+	}
+	return sb.String(), equalSignString + check
+}
+
+// saveCodeFromQR maps the text of a scanned QR code back to the save code
+func saveCodeFromQR(text string) (string, bool) {
+	if len(text) < 2 || text[0] != qrAlphanumeric[0] {
+		return emptyString, false
+	}
+	payload, check, found := strings.Cut(text[1:], equalSignString)
+	if !found {
+		return emptyString, false
+	}
+	mapped := make([]byte, len(payload))
+	for i := 0; i < len(payload); i++ {
+		index := strings.IndexByte(qrAlphanumeric, payload[i])
+		if index < 0 {
+			return emptyString, false
+		}
+		mapped[i] = base45Chars[index]
+	}
+	return string(mapped) + equalSignString + check, true
+}
+
+func newQRSaveCode(code string) (qrMatrix, error) {
+	alphanumeric, tail := qrSaveText(code)
+
+	bits := appendQRAlphanumeric(nil, alphanumeric)
+	bits = appendQRBits(bits, qrModeByte, 4)
+	bits = appendQRBits(bits, len(tail), 8)
+	for i := 0; i < len(tail); i++ {
+		bits = appendQRBits(bits, int(tail[i]), 8)
+	}
+
+	for i, version := range qrVersions {
+		dataCodewords := version.Total - version.ECPerBlock*version.Blocks
+		if len(bits) <= dataCodewords*8 {
+			return buildQR(i+1, version, qrCodewords(bits, dataCodewords)), nil
+		}
+	}
+	return qrMatrix{}, fmt.Errorf("save code too long for a QR code of version %d", len(qrVersions))
+}
+
+// appendQRAlphanumeric adds a segment in alphanumeric mode, two characters in 11 bits
+func appendQRAlphanumeric(bits []bool, text string) []bool {
+	bits = appendQRBits(bits, qrModeAlphanumeric, 4)
+	bits = appendQRBits(bits, len(text), 9)
+	for i := 0; i < len(text); i += 2 {
+		first := strings.IndexByte(qrAlphanumeric, text[i])
+		if i+1 == len(text) {
+			return appendQRBits(bits, first, 6)
+		}
+		bits = appendQRBits(bits, first*45+strings.IndexByte(qrAlphanumeric, text[i+1]), 11)
+	}
+	return bits
+}
+
+func appendQRBits(bits []bool, value, length int) []bool {
+	for i := length - 1; i >= 0; i-- {
+		bits = append(bits, (value>>i)&1 != 0)
+	}
+	return bits
+}
+
+// qrCodewords ends the bits with the terminator and fills the rest with the pad bytes 0xEC, 0x11
+func qrCodewords(bits []bool, dataCodewords int) []byte {
+	bits = appendQRBits(bits, 0, min(4, dataCodewords*8-len(bits)))
+	bits = appendQRBits(bits, 0, (8-len(bits)%8)%8)
+	data := make([]byte, 0, dataCodewords)
+	for i := 0; i < len(bits); i += 8 {
+		var codeword byte
+		for _, bit := range bits[i : i+8] {
+			codeword <<= 1
+			if bit {
+				codeword |= 1
+			}
+		}
+		data = append(data, codeword)
+	}
+	for pad := byte(0xEC); len(data) < dataCodewords; pad ^= 0xEC ^ 0x11 {
+		data = append(data, pad)
+	}
+	return data
+}
+
+// ------------------- QR Error Correction --------------------
+
+// qrInterleave adds the Reed-Solomon codewords to each block and interleaves the blocks.
+// All blocks of qrVersions have the same length, so no block is one codeword shorter.
+func qrInterleave(version qrVersion, data []byte) []byte {
+	divisor := qrDivisor(version.ECPerBlock)
+	dataPerBlock := len(data) / version.Blocks
+	blocks := make([][]byte, version.Blocks)
+	for i := range blocks {
+		block := data[i*dataPerBlock : (i+1)*dataPerBlock]
+		blocks[i] = append(append([]byte{}, block...), qrRemainder(block, divisor)...)
+	}
+	result := make([]byte, 0, version.Total)
+	for i := range blocks[0] {
+		for _, block := range blocks {
+			result = append(result, block[i])
+		}
+	}
+	return result
+}
+
+// qrDivisor is the generator polynomial of the given degree, highest coefficient first and the leading 1 left out
+func qrDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = gfMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = gfMultiply(root, 0x02)
+	}
+	return result
+}
+
+func qrRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i := range result {
+			result[i] ^= gfMultiply(divisor[i], factor)
+		}
+	}
+	return result
+}
+
+// gfMultiply multiplies in GF(2^8) modulo x^8 + x^4 + x^3 + x^2 + 1
+func gfMultiply(x, y byte) byte {
+	z := 0
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11D)
+		z ^= int((y>>i)&1) * int(x)
+	}
+	return byte(z)
+}
+
+// ------------------- QR Matrix ------------------------------
+
+func buildQR(versionNumber int, version qrVersion, data []byte) qrMatrix {
+	size := versionNumber*4 + 17
+	q := qrMatrix{Size: size, Modules: make([]bool, size*size), Function: make([]bool, size*size)}
+
+	for i := 0; i < size; i++ {
+		q.setFunction(6, i, i%2 == 0)
+		q.setFunction(i, 6, i%2 == 0)
+	}
+	q.drawFinder(3, 3)
+	q.drawFinder(size-4, 3)
+	q.drawFinder(3, size-4)
+	if versionNumber >= 2 { // up to version 6 the only alignment pattern not on a finder
+		q.drawAlignment(size-7, size-7)
+	}
+	q.drawFormatBits(0) // reserves the format modules before the data goes in
+
+	q.drawCodewords(qrInterleave(version, data))
+
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		q.applyMask(mask)
+		q.drawFormatBits(mask)
+		penalty := q.penalty()
+		if bestPenalty < 0 || penalty < bestPenalty {
+			best, bestPenalty = mask, penalty
+		}
+		q.applyMask(mask) // masking twice undoes it
+	}
+	q.applyMask(best)
+	q.drawFormatBits(best)
+	return q
+}
+
+func (q qrMatrix) dark(x, y int) bool {
+	return x >= 0 && y >= 0 && x < q.Size && y < q.Size && q.Modules[y*q.Size+x]
+}
+
+func (q qrMatrix) setFunction(x, y int, dark bool) {
+	q.Modules[y*q.Size+x] = dark
+	q.Function[y*q.Size+x] = true
+}
+
+func (q qrMatrix) drawFinder(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			distance := max(abs(dx), abs(dy))
+			if x+dx >= 0 && x+dx < q.Size && y+dy >= 0 && y+dy < q.Size {
+				q.setFunction(x+dx, y+dy, distance != 2 && distance != 4)
+			}
+		}
+	}
+}
+
+func (q qrMatrix) drawAlignment(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			q.setFunction(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+// qrFormatBits is level M and the mask, protected by a BCH code and masked with 0x5412
+func qrFormatBits(mask int) int {
+	data := qrLevelM<<3 | mask
+	remainder := data
+	for i := 0; i < 10; i++ {
+		remainder = (remainder << 1) ^ ((remainder >> 9) * 0x537)
+	}
+	return (data<<10 | remainder) ^ 0x5412
+}
+
+// drawFormatBits writes the format bits twice, around the top left finder and split
+// between the other two
+func (q qrMatrix) drawFormatBits(mask int) {
+	bits := qrFormatBits(mask)
+	bit := func(i int) bool { return (bits>>i)&1 != 0 }
+
+	for i := 0; i <= 5; i++ {
+		q.setFunction(8, i, bit(i))
+	}
+	q.setFunction(8, 7, bit(6))
+	q.setFunction(8, 8, bit(7))
+	q.setFunction(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		q.setFunction(14-i, 8, bit(i))
+	}
+	for i := 0; i < 8; i++ {
+		q.setFunction(q.Size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		q.setFunction(8, q.Size-15+i, bit(i))
+	}
+	q.setFunction(8, q.Size-8, true) // the dark module
+}
+
+// drawCodewords fills the modules in the zigzag of two columns, right to left, skipping the timing column
+func (q qrMatrix) drawCodewords(data []byte) {
+	i := 0
+	for right := q.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vertical := 0; vertical < q.Size; vertical++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				y := vertical
+				if (right+1)&2 == 0 {
+					y = q.Size - 1 - vertical // upward
+				}
+				if !q.Function[y*q.Size+x] && i < len(data)*8 {
+					q.Modules[y*q.Size+x] = (data[i>>3]>>(7-i&7))&1 != 0
+					i++
+				}
+			}
+		}
+	}
+}
+
+func (q qrMatrix) applyMask(mask int) {
+	for y := 0; y < q.Size; y++ {
+		for x := 0; x < q.Size; x++ {
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if invert && !q.Function[y*q.Size+x] {
+				q.Modules[y*q.Size+x] = !q.Modules[y*q.Size+x]
+			}
+		}
+	}
+}
+
+// penalty scores a mask by the four rules of the standard: runs, 2x2 blocks,
+// finder-like patterns and the balance of dark and light modules
+func (q qrMatrix) penalty() int {
+	result := 0
+	darkCount := 0
+	for a := 0; a < q.Size; a++ {
+		rowRun, columnRun := 1, 1
+		for b := 0; b < q.Size; b++ {
+			if q.dark(b, a) {
+				darkCount++
+			}
+			if b > 0 {
+				rowRun, result = qrRun(q.dark(b, a) == q.dark(b-1, a), rowRun, result)
+				columnRun, result = qrRun(q.dark(a, b) == q.dark(a, b-1), columnRun, result)
+			}
+			if a > 0 && b > 0 {
+				color := q.dark(b, a)
+				if color == q.dark(b-1, a) && color == q.dark(b, a-1) && color == q.dark(b-1, a-1) {
+					result += 3
+				}
+			}
+			if q.finderLike(b, a, 1, 0) {
+				result += 40
+			}
+			if q.finderLike(a, b, 0, 1) {
+				result += 40
+			}
+		}
+	}
+	total := q.Size * q.Size
+	return result + (abs(darkCount*20-total*10)+total-1)/total*10 - 10
+}
+
+// qrRun scores 3 for a run of five modules of one color and 1 for every further module
+func qrRun(same bool, run, result int) (int, int) {
+	if !same {
+		return 1, result
+	}
+	run++
+	if run == 5 {
+		result += 3
+	} else if run > 5 {
+		result++
+	}
+	return run, result
+}
+
+// finderLike looks for dark-light-dark-dark-dark-light-dark with four light modules on either side
+func (q qrMatrix) finderLike(x, y, dx, dy int) bool {
+	pattern := [11]bool{true, false, true, true, true, false, true, false, false, false, false}
+	forward, backward := true, true
+	for i, dark := range pattern {
+		module := q.dark(x+i*dx, y+i*dy)
+		if x+i*dx >= q.Size || y+i*dy >= q.Size {
+			return false
+		}
+		forward = forward && module == dark
+		backward = backward && module == pattern[len(pattern)-1-i]
+	}
+	return forward || backward
+}
+
+// ------------------- QR Rendering ---------------------------
+
+// qrQuietZone picks the widest quiet zone up to the four modules of the standard that fits,
+// -1 when the code doesn't fit at all. Two module rows share one line of half blocks.
+func qrQuietZone(size, width, height int) int {
+	for quiet := 4; quiet >= 1; quiet-- {
+		side := size + 2*quiet
+		if side <= width && (side+1)/2 <= height {
+			return quiet
+		}
+	}
+	return -1
+}
+
+// halfBlockLines draws the light modules as blocks, as terminals mostly show light text on a
+// dark background; the quiet zone around the code is light as well
+func (q qrMatrix) halfBlockLines(quiet int) []string {
+	side := q.Size + 2*quiet
+	lines := make([]string, 0, (side+1)/2)
+	var sb strings.Builder
+	for y := -quiet; y < q.Size+quiet; y += 2 {
+		sb.Reset()
+		for x := -quiet; x < q.Size+quiet; x++ {
+			top := !q.dark(x, y)
+			bottom := y+1 < q.Size+quiet && !q.dark(x, y+1)
+			switch {
+			case top && bottom:
+				sb.WriteString("█")
+			case top:
+				sb.WriteString("▀")
+			case bottom:
+				sb.WriteString("▄")
+			default:
+				sb.WriteByte(singleSpaceRune)
+			}
+		}
+		lines = append(lines, sb.String())
+	}
+	return lines
+}
+
+// renderQR shows no code while a hand is in progress, only a slot keeps such a hand
+// and the slot is replaced by the outcome of the hand
+func renderQR(b []byte, m blackjackModel) string {
+	if m.Game.Phase == phasePlay {
+		b = m.wrapAndPad(b, m.UiText.CodeMidHand)
+		b = append(b, newlineRune)
+		b = append(b, newlineRune)
+		b = m.wrapAndPad(b, m.UiText.QRHelp)
+		b = append(b, newlineRune)
+		return string(m.verticalPad(b))
+	}
+	code, err := encodeSave(m.Game)
+	if err != nil {
+		b = m.wrapAndPad(b, m.UiText.SlotWriteFailed+err.Error())
+		b = append(b, newlineRune)
+		return string(m.verticalPad(b))
+	}
+	qr, err := newQRSaveCode(code)
+	quiet := qrQuietZone(qr.Size, m.UiState.WindowWidth-2, m.UiState.WindowHeight-6)
+	switch {
+	case err != nil:
+		b = m.wrapAndPad(b, err.Error())
+		b = append(b, newlineRune)
+	case quiet < 0:
+		b = m.wrapAndPad(b, m.UiText.QRTooSmall)
+		b = append(b, newlineRune)
+	default:
+		for _, line := range qr.halfBlockLines(quiet) {
+			b = m.wrapAndPad(b, line)
+			b = append(b, newlineRune)
+		}
+	}
+	b = append(b, newlineRune)
+	b = m.wrapAndPad(b, code)
+	b = append(b, newlineRune)
+	b = m.wrapAndPad(b, m.UiText.QRHelp)
+	b = append(b, newlineRune)
+	return string(m.verticalPad(b))
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+// The vector is the HELLO WORLD example of version 1-M from the QR code standard
+func TestQRHelloWorldCodewords(t *testing.T) {
+	version := qrVersions[0]
+	dataCodewords := version.Total - version.ECPerBlock*version.Blocks
+	data := qrCodewords(appendQRAlphanumeric(nil, "HELLO WORLD"), dataCodewords)
+	wantData := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	if !bytes.Equal(data, wantData) {
+		t.Fatalf("data codewords %v, want %v", data, wantData)
+	}
+	ec := qrRemainder(data, qrDivisor(version.ECPerBlock))
+	wantEC := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	if !bytes.Equal(ec, wantEC) {
+		t.Errorf("error correction codewords %v, want %v", ec, wantEC)
+	}
+	if all := qrInterleave(version, data); !bytes.Equal(all, append(wantData, wantEC...)) {
+		t.Errorf("one block is not interleaved: %v", all)
+	}
+}
+
+func TestQRInterleavesBlocks(t *testing.T) {
+	version := qrVersions[3] // two blocks
+	dataCodewords := version.Total - version.ECPerBlock*version.Blocks
+	data := make([]byte, dataCodewords)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	all := qrInterleave(version, data)
+	if len(all) != version.Total {
+		t.Fatalf("%d codewords, want %d", len(all), version.Total)
+	}
+	half := dataCodewords / 2
+	first := qrRemainder(data[:half], qrDivisor(version.ECPerBlock))
+	second := qrRemainder(data[half:], qrDivisor(version.ECPerBlock))
+	for i := range half {
+		if all[2*i] != data[i] || all[2*i+1] != data[half+i] {
+			t.Fatalf("data codeword %d is not interleaved", i)
+		}
+	}
+	for i := range version.ECPerBlock {
+		if all[dataCodewords+2*i] != first[i] || all[dataCodewords+2*i+1] != second[i] {
+			t.Fatalf("error correction codeword %d is not interleaved", i)
+		}
+	}
+}
+
+// The format bits of level M for the masks 0 to 7, from the table of the standard
+func TestQRFormatBits(t *testing.T) {
+	want := []int{0x5412, 0x5125, 0x5E7C, 0x5B4B, 0x45F9, 0x40CE, 0x4F97, 0x4AA0}
+	for mask, bits := range want {
+		if got := qrFormatBits(mask); got != bits {
+			t.Errorf("mask %d: format bits %015b, want %015b", mask, got, bits)
+		}
+	}
+}
+
+// readFormatBits reads both copies of the format bits in the order drawFormatBits writes them
+func readFormatBits(q qrMatrix) (first, second int) {
+	set := func(bits *int, i int, dark bool) {
+		if dark {
+			*bits |= 1 << i
+		}
+	}
+	for i := 0; i <= 5; i++ {
+		set(&first, i, q.dark(8, i))
+	}
+	set(&first, 6, q.dark(8, 7))
+	set(&first, 7, q.dark(8, 8))
+	set(&first, 8, q.dark(7, 8))
+	for i := 9; i < 15; i++ {
+		set(&first, i, q.dark(14-i, 8))
+	}
+	for i := 0; i < 8; i++ {
+		set(&second, i, q.dark(q.Size-1-i, 8))
+	}
+	for i := 8; i < 15; i++ {
+		set(&second, i, q.dark(8, q.Size-15+i))
+	}
+	return first, second
+}
+
+func TestQRSaveCodeVersions(t *testing.T) {
+	longest := gameState{RandomSeed: 0xFFFFFFFF, ReshuffleThreshold: 0xFFFF, CardsDealt: 0xFFFF, NumberDecks: 0xFF,
+		HitOnSoft17: true, NeedReshuffle: true, PlayerMoney: 0xFFFF, Payout: 0xFF}
+	checksumCode, err := longest.encodeWithChecksum()
+	if err != nil {
+		t.Fatal(err)
+	}
+	macCode := longest.encodeWithMAC(bytes.Repeat([]byte{0xA5}, 32))
+	for _, test := range []struct {
+		code    string
+		version int
+	}{
+		{checksumCode, 2},
+		{macCode, 3},
+	} {
+		q, err := newQRSaveCode(test.code)
+		if err != nil {
+			t.Fatalf("%s: %v", test.code, err)
+		}
+		if q.Size != test.version*4+17 {
+			t.Errorf("%s: %d modules, want version %d with %d", test.code, q.Size, test.version, test.version*4+17)
+		}
+		first, second := readFormatBits(q)
+		if first != second {
+			t.Errorf("%s: the copies of the format bits differ: %015b and %015b", test.code, first, second)
+		}
+		valid := false
+		for mask := range 8 {
+			valid = valid || first == qrFormatBits(mask)
+		}
+		if !valid {
+			t.Errorf("%s: format bits %015b are not level M", test.code, first)
+		}
+		if !q.dark(8, q.Size-8) {
+			t.Errorf("%s: the dark module is light", test.code)
+		}
+
+		alphanumeric, tail := qrSaveText(test.code)
+		code, ok := saveCodeFromQR(alphanumeric + tail)
+		if !ok || code != test.code {
+			t.Errorf("%s: the QR text reads back as %q", test.code, code)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"math/rand/v2"
+)
+
+// ------------------- Reinforcement Learning Environment -----
+//
+// blackjackEnv is a gym-style environment over the rules in rules.go: Reset(seed), Step(actions),
+// observation vectors and legal-action masks for a batch of independent tables.
+// It stays in package main like the rest of the repository, so training code is added to this
+// package as another file (or talks to the bot command from another language).
+//
+// One episode is one hand with a bet of envBet. Hands that end on the deal (naturals) never
+// need a decision, their reward is reported in SettledOnDeal instead. A table that finishes
+// a hand deals the next one right away, so every table always waits for an action.
+// Step and Reset reuse the buffers of the environment and don't allocate; the slices returned
+// by Observations, Masks, Rewards and Dones are overwritten by the next call.
+
+func newBlackjackEnv(tables int, cfg simConfig) *blackjackEnv {
+	e := &blackjackEnv{
+		tables:        make([]envTable, tables),
+		observations:  make([]float32, tables*envObservationSize),
+		masks:         make([]bool, tables*len(envActions)),
+		rewards:       make([]float32, tables),
+		dones:         make([]bool, tables),
+		settledOnDeal: make([]float32, tables),
+	}
+	totalCards := int(cfg.NumberDecks) * 52
+	for i := range e.tables {
+		t := &e.tables[i]
+		t.shoe = make([]card, 0, totalCards)
+		t.pcg = rand.NewPCG(0, 0)
+		t.rng = rand.New(t.pcg)
+		t.gs = gameState{
+			NumberDecks:        cfg.NumberDecks,
+			Payout:             cfg.Payout,
+			HitOnSoft17:        cfg.HitOnSoft17,
+			ReshuffleThreshold: reshuffleThreshold(cfg.NumberDecks, cfg.Penetration),
+			PlayerCards:        make([]card, 0, 22), // 22xA
+			DealerCards:        make([]card, 0, 13), // 7xA + 1x5 + 5xA
+		}
+	}
+	return e
+}
+
+// Reset seeds table i with the sub-seed i of seed, shuffles fresh shoes and deals the first hands.
+// The same seed and the same actions give the same cards and rewards.
+func (e *blackjackEnv) Reset(seed uint64) []float32 {
+	for i := range e.tables {
+		t := &e.tables[i]
+		t.pcg.Seed(workerSeed(seed, i), simStream)
+		t.shuffle()
+		e.rewards[i] = 0
+		e.dones[i] = false
+		e.settledOnDeal[i] = t.deal()
+		e.observe(i)
+	}
+	return e.observations
+}
+
+// Step plays actions[i] (an index into envActions) at table i. An action the mask forbids
+// is played as a hit, like the simulator does. actions needs one entry per table, otherwise
+// no table moves and Step returns an error.
+func (e *blackjackEnv) Step(actions []int) ([]float32, []float32, []bool, error) {
+	if len(actions) != len(e.tables) {
+		return e.observations, e.rewards, e.dones, fmt.Errorf("got %d actions for %d tables", len(actions), len(e.tables))
+	}
+	for i := range e.tables {
+		t := &e.tables[i]
+		action := actionHit
+		if actions[i] >= 0 && actions[i] < len(envActions) && legalAction(t.gs, envActions[actions[i]]) {
+			action = envActions[actions[i]]
+		}
+		t.gs = applyAction(t.gs, action)
+
+		e.rewards[i] = 0
+		e.dones[i] = t.gs.Phase == phaseEnd
+		e.settledOnDeal[i] = 0
+		if e.dones[i] {
+			e.rewards[i] = t.finish()
+			e.settledOnDeal[i] = t.deal()
+		}
+		e.observe(i)
+	}
+	return e.observations, e.rewards, e.dones, nil
+}
+
+func (e *blackjackEnv) Observations() []float32  { return e.observations }
+func (e *blackjackEnv) Masks() []bool            { return e.masks }
+func (e *blackjackEnv) Rewards() []float32       { return e.rewards }
+func (e *blackjackEnv) Dones() []bool            { return e.dones }
+func (e *blackjackEnv) SettledOnDeal() []float32 { return e.settledOnDeal }
+
+// observe writes the observation and the mask of table i. Everything in it is visible to
+// the player: the own hand, the upcard and the cards seen since the shuffle.
+//
+//	0      player total / 21
+//	1      1 if the hand is soft
+//	2      number of player cards / 10
+//	3-12   upcard one-hot by cardIndex
+//	13     1 if double is legal
+//	14     Hi-Lo true count / 10
+//	15     share of the shoe dealt
+//	16-25  share of the unseen cards by cardIndex
+func (e *blackjackEnv) observe(i int) {
+	t := &e.tables[i]
+	obs := e.observations[i*envObservationSize : (i+1)*envObservationSize]
+	clear(obs)
+
+	total, soft := calculateSoftHand(t.gs.PlayerCards)
+	obs[0] = float32(total) / 21
+	if soft {
+		obs[1] = 1
+	}
+	obs[2] = float32(len(t.gs.PlayerCards)) / 10
+	upcard := cardIndex(t.gs.DealerCards[0])
+	obs[3+upcard] = 1
+	if canDouble(t.gs) {
+		obs[13] = 1
+	}
+
+	seen := t.seen
+	for _, c := range t.gs.PlayerCards {
+		seen[cardIndex(c)]++
+	}
+	seen[upcard]++
+	hiLo := builtinCountSystems[0]
+	running := 0
+	unseen := fullShoe(t.gs.NumberDecks)
+	for index, count := range seen {
+		running += hiLo.Tags[index] * count
+		unseen[index] -= count
+	}
+	obs[14] = float32(trueCount(running, t.gs)) / 10
+	totalCards := int(t.gs.NumberDecks) * 52
+	obs[15] = float32(t.gs.CardsDealt) / float32(totalCards)
+	remaining := unseen.total()
+	for index, count := range unseen {
+		obs[16+index] = float32(count) / float32(max(remaining, 1))
+	}
+
+	mask := e.masks[i*len(envActions) : (i+1)*len(envActions)]
+	for j, action := range envActions {
+		mask[j] = legalAction(t.gs, action)
+	}
+}
+
+// shuffle refills the shoe in place, newDeck would build a new generator every time
+func (t *envTable) shuffle() {
+	t.shoe = t.shoe[:0]
+	for range t.gs.NumberDecks {
+		t.shoe = append(t.shoe, bjCards.StandardDeck...)
+	}
+	shoe := t.shoe
+	t.rng.Shuffle(len(shoe), func(i, j int) {
+		shoe[i], shoe[j] = shoe[j], shoe[i]
+	})
+	t.gs.DrawStack = t.shoe
+	t.gs.CardsDealt = 0
+	t.gs.HiLoCount = 0
+	t.gs.NeedReshuffle = false
+	t.seen = shoeCounts{}
+}
+
+// deal starts hands until one needs a decision and returns the rewards of the hands that didn't
+func (t *envTable) deal() float32 {
+	var settled float32
+	for {
+		if t.gs.NeedReshuffle {
+			t.shuffle()
+		}
+		t.gs.PlayerMoney = simBankroll
+		t.gs = placeBet(t.gs, envBet)
+		t.gs = dealRound(t.gs)
+		if t.gs.Phase == phasePlay {
+			return settled
+		}
+		settled += t.finish()
+	}
+}
+
+// finish counts the cards of the finished hand as seen and returns its reward in bets,
+// derived from PlayerMoney after calculateMoney like in the simulator
+func (t *envTable) finish() float32 {
+	for _, c := range t.gs.PlayerCards {
+		t.seen[cardIndex(c)]++
+	}
+	for _, c := range t.gs.DealerCards {
+		t.seen[cardIndex(c)]++
+	}
+	return float32(int(t.gs.PlayerMoney)-simBankroll) / envBet
+}
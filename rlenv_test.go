@@ -0,0 +1,66 @@
+package main
+
+import (
+	"testing"
+)
+
+func newTestEnv(t *testing.T, tables int) *blackjackEnv {
+	t.Helper()
+	cfg, err := newSimConfig(1, payout32, false, 6, 75, "basic", 10, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newBlackjackEnv(tables, cfg)
+}
+
+func TestEnvStepRejectsWrongActionCount(t *testing.T) {
+	e := newTestEnv(t, 4)
+	e.Reset(1)
+	before := append([]float32(nil), e.Observations()...)
+	for _, actions := range [][]int{nil, make([]int, 3), make([]int, 5)} {
+		_, _, _, err := e.Step(actions)
+		if err == nil {
+			t.Errorf("%d actions for 4 tables pass", len(actions))
+		}
+	}
+	for i, value := range e.Observations() {
+		if value != before[i] {
+			t.Fatal("a rejected step moved a table")
+		}
+	}
+}
+
+func TestEnvStepAndResetDoNotAllocate(t *testing.T) {
+	e := newTestEnv(t, 8)
+	actions := make([]int, 8) // index 0 of envActions, hits until the hands end
+	var seed uint64
+	allocs := testing.AllocsPerRun(100, func() {
+		seed++
+		e.Reset(seed)
+		for range 5 {
+			_, _, _, err := e.Step(actions)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("Reset and Step allocate %.1f times per run", allocs)
+	}
+}
+
+func TestEnvReplaysSameSeed(t *testing.T) {
+	a, b := newTestEnv(t, 3), newTestEnv(t, 3)
+	a.Reset(42)
+	b.Reset(42)
+	actions := []int{0, 1, 2}
+	for range 50 {
+		_, rewardsA, _, _ := a.Step(actions)
+		_, rewardsB, _, _ := b.Step(actions)
+		for i := range rewardsA {
+			if rewardsA[i] != rewardsB[i] {
+				t.Fatalf("table %d: rewards %v and %v of the same seed", i, rewardsA[i], rewardsB[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ------------------- Ruin Command ---------------------------
+
+func runRuin(args []string) int {
+	flags := flag.NewFlagSet(commandRuin, flag.ContinueOnError)
+	f := defineSimFlags(flags, 1000, "rounds per session at most", 50, startingMoney)
+	policy := flags.String("policy", "flat", "bet policy: "+strings.Join(betPolicyNames(), ", "))
+	target := flags.Int("target", 2*startingMoney, "bankroll that ends a session as a success, 0 plays every session to -rounds")
+	sessions := flags.Int64("sessions", 10000, "number of Monte Carlo sessions")
+	err := flags.Parse(args)
+	if err != nil {
+		return 2
+	}
+
+	cfg, err := f.config(*policy)
+	if err == nil {
+		err = validateRuin(cfg, *target, *sessions)
+	}
+	if err != nil {
+		fmt.Println("ruin: " + err.Error())
+		return 2
+	}
+
+	start := time.Now()
+	pilot := cfg
+	pilot.Rounds = ruinPilotRounds
+	perRound := simulate(pilot)
+	stats := simulateSessions(cfg, *target, *sessions)
+	printRuinReport(os.Stdout, cfg, *target, perRound, stats, time.Since(start))
+	return 0
+}
+
+func validateRuin(cfg simConfig, target int, sessions int64) error {
+	switch {
+	case target != 0 && target <= cfg.Bankroll:
+		return fmt.Errorf("target must be above the bankroll of %d or 0, got %d", cfg.Bankroll, target)
+	case sessions < 1:
+		return fmt.Errorf("sessions must be at least 1, got %d", sessions)
+	}
+	return nil
+}
+
+// ------------------- Monte Carlo Sessions -------------------
+
+// simulateSessions splits the sessions into chunks of ruinChunkSessions like simulate splits
+// rounds, so the results are the same for the same seed whatever the number of workers.
+// Every session starts with cfg.Bankroll and ends on ruin, on the target or after cfg.Rounds.
+func simulateSessions(cfg simConfig, target int, sessions int64) ruinStats {
+	chunks := int((sessions + ruinChunkSessions - 1) / ruinChunkSessions)
+	results := make([]ruinStats, chunks)
+	var wg sync.WaitGroup
+	for worker := range min(cfg.Workers, chunks) {
+		wg.Go(func() {
+			for chunk := worker; chunk < chunks; chunk += cfg.Workers {
+				share := min(ruinChunkSessions, sessions-int64(chunk)*ruinChunkSessions)
+				results[chunk] = sessionWorker(cfg, target, workerSeed(cfg.Seed, chunk), share)
+			}
+		})
+	}
+	wg.Wait()
+
+	var stats ruinStats
+	for _, result := range results {
+		stats = stats.merge(result)
+	}
+	return stats
+}
+
+// sessionWorker plays the sessions of a chunk one after another at the same table, so the shoe and
+// its count carry over from one session to the next like they would in a casino
+func sessionWorker(cfg simConfig, target int, seed uint64, sessions int64) ruinStats {
+	rng := rand.New(rand.NewPCG(seed, simStream))
+	gs := newSimTable(cfg, rng)
+	b := newBettor(cfg.Policy, int(cfg.Bet), cfg.MinBet, cfg.MaxBet, cfg.Spread, cfg.KellyFraction,
+		baselineEdge(cfg.Payout, cfg.HitOnSoft17, cfg.NumberDecks))
+
+	stats := ruinStats{Finals: make([]int, 0, sessions)}
+	for i := range stats.Trajectories {
+		stats.Trajectories[i] = make([]int, 0, sessions)
+	}
+	var net int
+	for session := int64(0); session < sessions; session++ {
+		b.restart()
+		bankroll := cfg.Bankroll
+		round := int64(0)
+		checkpoint := 0
+		for ; round < cfg.Rounds; round++ {
+			for checkpoint <= ruinChartWidth && checkpointRound(cfg.Rounds, checkpoint) <= round {
+				stats.Trajectories[checkpoint] = append(stats.Trajectories[checkpoint], bankroll)
+				checkpoint++
+			}
+			if bankroll < cfg.MinBet || (target > 0 && bankroll >= target) {
+				break
+			}
+			gs = reshuffleIfNeeded(gs, rng.Uint32())
+			bet := b.next(gs, bankroll)
+			gs, net = simRound(gs, cfg, uint16(bet), bankroll, b.Running)
+			bankroll += net
+			b.record(gs, bet, net)
+		}
+		for ; checkpoint <= ruinChartWidth; checkpoint++ {
+			stats.Trajectories[checkpoint] = append(stats.Trajectories[checkpoint], bankroll)
+		}
+
+		stats.Sessions++
+		stats.Rounds += round
+		switch {
+		case bankroll < cfg.MinBet:
+			stats.Ruined++
+		case target > 0 && bankroll >= target:
+			stats.Reached++
+		}
+		stats.Finals = append(stats.Finals, bankroll)
+	}
+	return stats
+}
+
+func checkpointRound(rounds int64, checkpoint int) int64 {
+	return rounds * int64(checkpoint) / ruinChartWidth
+}
+
+// merge appends in chunk order, which keeps the percentiles reproducible
+func (s ruinStats) merge(o ruinStats) ruinStats {
+	s.Sessions += o.Sessions
+	s.Ruined += o.Ruined
+	s.Reached += o.Reached
+	s.Rounds += o.Rounds
+	s.Finals = append(s.Finals, o.Finals...)
+	for i := range s.Trajectories {
+		s.Trajectories[i] = append(s.Trajectories[i], o.Trajectories[i]...)
+	}
+	return s
+}
+
+// percentile expects sorted values
+func percentile(sorted []int, p float64) int {
+	if len(sorted) == 0 {
+		return 0
+	}
+	return sorted[int(p*float64(len(sorted)-1)+0.5)]
+}
+
+// ------------------- Analytic Approximations ----------------
+
+// ruinProbability treats the bankroll as a Brownian motion with the mean and variance
+// per round of the pilot run. With a target it is the classic two barrier result,
+// without one it is the chance to ever go broke, which is certain without an edge.
+func ruinProbability(mean, variance float64, bankroll, target int) float64 {
+	if variance <= 0 {
+		return math.NaN()
+	}
+	if target == 0 {
+		if mean <= 0 {
+			return 1
+		}
+		return math.Exp(-2 * mean * float64(bankroll) / variance)
+	}
+	if math.Abs(mean) < 1e-12 {
+		return 1 - float64(bankroll)/float64(target)
+	}
+	a := math.Exp(-2 * mean * float64(bankroll) / variance)
+	b := math.Exp(-2 * mean * float64(target) / variance)
+	return (a - b) / (1 - b)
+}
+
+// normalCDF is the standard normal distribution function
+func normalCDF(x float64) float64 {
+	return 0.5 * math.Erfc(-x/math.Sqrt2)
+}
+
+// ------------------- Ruin Report ----------------------------
+
+func printRuinReport(w io.Writer, cfg simConfig, target int, perRound simStats, stats ruinStats, elapsed time.Duration) {
+	mean := perRound.ChipMean
+	variance := perRound.chipVariance()
+	n := float64(stats.Sessions)
+
+	_, _ = fmt.Fprintf(w, "Rules:          %s\n", rulesSummary(cfg.Payout, cfg.HitOnSoft17, cfg.NumberDecks, cfg.Penetration))
+	_, _ = fmt.Fprintf(w, "Strategy:       %s\n", cfg.StrategyName)
+	_, _ = fmt.Fprintf(w, "Bet:            %s\n", betSummary(cfg))
+	targetLabel := "none"
+	if target > 0 {
+		targetLabel = strconv.Itoa(target)
+	}
+	_, _ = fmt.Fprintf(w, "Bankroll:       %d, target %s, at most %d rounds per session\n", cfg.Bankroll, targetLabel, cfg.Rounds)
+	_, _ = fmt.Fprintf(w, "Per round:      %.3f chips, std dev %.2f chips (%d pilot rounds)\n", mean, math.Sqrt(variance), perRound.Rounds)
+	_, _ = fmt.Fprintf(w, "Seed:           %d (%d workers)\n", cfg.Seed, cfg.Workers)
+	_, _ = fmt.Fprintf(w, "Sessions:       %d (%s)\n\n", stats.Sessions, elapsed.Round(time.Millisecond))
+
+	ruined := float64(stats.Ruined) / n
+	reached := float64(stats.Reached) / n
+	_, _ = fmt.Fprintf(w, "%-24s %10s %18s\n", "Probability", "Analytic", "Monte Carlo")
+	if target > 0 {
+		analytic := ruinProbability(mean, variance, cfg.Bankroll, target)
+		_, _ = fmt.Fprintf(w, "%-24s %9.2f%% %9.2f%% ± %.2f%%\n", "ruin before target", 100*analytic, 100*ruined, 100*proportionMargin(ruined, n))
+		_, _ = fmt.Fprintf(w, "%-24s %9.2f%% %9.2f%% ± %.2f%%\n", "target before ruin", 100*(1-analytic), 100*reached, 100*proportionMargin(reached, n))
+		_, _ = fmt.Fprintf(w, "%-24s %10s %9.2f%%\n", "out of rounds", "-", 100*(1-ruined-reached))
+	} else {
+		_, _ = fmt.Fprintf(w, "%-24s %10s %9.2f%% ± %.2f%%\n", "ruin within the rounds", "-", 100*ruined, 100*proportionMargin(ruined, n))
+	}
+	_, _ = fmt.Fprintf(w, "%-24s %9.2f%% %10s\n", "ruin, playing forever", 100*ruinProbability(mean, variance, cfg.Bankroll, 0), "-")
+
+	// the normal approximation ignores that a session stops at ruin or the target
+	rounds := float64(cfg.Rounds)
+	sd := math.Sqrt(variance * rounds)
+	_, _ = fmt.Fprintf(w, "\nAfter %d rounds without stopping (normal approximation):\n", cfg.Rounds)
+	_, _ = fmt.Fprintf(w, "  expected result %+.1f chips, std dev %.1f chips\n", mean*rounds, sd)
+	_, _ = fmt.Fprintf(w, "  chance to be down the whole bankroll %.2f %%\n", 100*normalCDF((-float64(cfg.Bankroll)-mean*rounds)/sd))
+	_, _ = fmt.Fprintf(w, "Average session: %.1f rounds\n\n", float64(stats.Rounds)/n)
+
+	finals := slices.Clone(stats.Finals)
+	slices.Sort(finals)
+	_, _ = fmt.Fprintln(w, "Final bankroll:")
+	for _, p := range [5]float64{0.05, 0.25, 0.5, 0.75, 0.95} {
+		_, _ = fmt.Fprintf(w, "  %2.0f %% of the sessions end at or below %d\n", 100*p, percentile(finals, p))
+	}
+	printFinalHistogram(w, finals, target)
+	_, _ = fmt.Fprintln(w)
+	printTrajectoryChart(w, cfg, stats)
+}
+
+// proportionMargin is the 95 % confidence margin of a Monte Carlo frequency
+func proportionMargin(p, n float64) float64 {
+	return 1.96 * math.Sqrt(p*(1-p)/n)
+}
+
+func printFinalHistogram(w io.Writer, sorted []int, target int) {
+	top := target
+	if top == 0 {
+		top = sorted[len(sorted)-1] + 1
+	}
+	width := max(10, (top+10*ruinHistogramBins-1)/(10*ruinHistogramBins)*10) // whole chips of 10
+	var bins [ruinHistogramBins + 1]int
+	for _, final := range sorted {
+		bins[min(final/width, ruinHistogramBins)]++
+	}
+	for i, count := range bins {
+		if count == 0 {
+			continue
+		}
+		frequency := float64(count) / float64(len(sorted))
+		label := fmt.Sprintf("%d-%d", i*width, (i+1)*width-1)
+		if i == ruinHistogramBins {
+			label = ">= " + strconv.Itoa(i*width)
+		}
+		_, _ = fmt.Fprintf(w, "  %-12s %8.2f %% %s\n", label, 100*frequency, histogramBar(frequency))
+	}
+}
+
+// printTrajectoryChart draws the median bankroll as * inside the band of the
+// 10th to 90th percentile, one column per checkpoint
+func printTrajectoryChart(w io.Writer, cfg simConfig, stats ruinStats) {
+	var low, median, high [ruinChartWidth + 1]int
+	top := 1
+	for i, values := range stats.Trajectories {
+		sorted := slices.Clone(values)
+		slices.Sort(sorted)
+		low[i], median[i], high[i] = percentile(sorted, 0.1), percentile(sorted, 0.5), percentile(sorted, 0.9)
+		top = max(top, high[i])
+	}
+	step := float64(top) / ruinChartHeight
+
+	_, _ = fmt.Fprintln(w, "Bankroll over the rounds (* median, : 10th to 90th percentile):")
+	for row := ruinChartHeight; row >= 0; row-- {
+		floor := float64(row) * step
+		var line strings.Builder
+		for i := range median {
+			switch {
+			case float64(median[i]) >= floor && float64(median[i]) < floor+step:
+				line.WriteByte('*')
+			case float64(high[i]) >= floor && float64(low[i]) < floor+step:
+				line.WriteByte(':')
+			default:
+				line.WriteByte(' ')
+			}
+		}
+		_, _ = fmt.Fprintf(w, "%7.0f |%s\n", floor, strings.TrimRight(line.String(), " "))
+	}
+	_, _ = fmt.Fprintf(w, "%7s +%s\n", "", strings.Repeat("-", ruinChartWidth+1))
+	last := strconv.FormatInt(cfg.Rounds, 10)
+	_, _ = fmt.Fprintf(w, "%7s  0%s%s rounds\n", "", strings.Repeat(" ", ruinChartWidth-len(last)), last)
+}
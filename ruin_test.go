@@ -0,0 +1,130 @@
+package main
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// ruinConfig is the default ruin command, 100 chips at the 10-50 table
+func ruinConfig(t *testing.T, rounds int64, policy string) simConfig {
+	t.Helper()
+	cfg, err := newSimConfig(rounds, payout32, false, 6, 75, "basic", 10, 7, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = withBetPolicy(cfg, policy, 10, 50, startingMoney, 4, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func TestSessionsAreSameForAnyWorkerCount(t *testing.T) {
+	cfg := ruinConfig(t, 200, "flat")
+	sessions := int64(3*ruinChunkSessions + 5)
+	want := simulateSessions(cfg, 2*startingMoney, sessions)
+	if want.Sessions != sessions {
+		t.Fatalf("played %d of %d sessions", want.Sessions, sessions)
+	}
+	for _, workers := range []int{2, 3, 8} {
+		cfg.Workers = workers
+		got := simulateSessions(cfg, 2*startingMoney, sessions)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%d workers: %d ruined, %d reached, %d rounds; 1 worker: %d ruined, %d reached, %d rounds",
+				workers, got.Ruined, got.Reached, got.Rounds, want.Ruined, want.Reached, want.Rounds)
+		}
+	}
+}
+
+func TestRuinProbability(t *testing.T) {
+	tests := []struct {
+		name             string
+		mean, variance   float64
+		bankroll, target int
+		want             float64
+	}{
+		{"fair game to double", 0, 1.3, 100, 200, 0.5},
+		{"fair game forever", 0, 1.3, 100, 0, 1},
+		{"losing game forever", -0.05, 1.3, 100, 0, 1},
+		{"winning game forever", 0.01, 1.3, 100, 0, math.Exp(-2 * 0.01 * 100 / 1.3)},
+		{"no variance", 0.01, 0, 100, 200, math.NaN()},
+	}
+	for _, test := range tests {
+		got := ruinProbability(test.mean, test.variance, test.bankroll, test.target)
+		if math.IsNaN(test.want) != math.IsNaN(got) || !math.IsNaN(got) && math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+
+	losing := ruinProbability(-0.05, 1.3, 100, 200)
+	winning := ruinProbability(0.05, 1.3, 100, 200)
+	if !(winning < 0.5 && 0.5 < losing && losing < 1) {
+		t.Errorf("to double 100 chips: %v with an edge, %v against one", winning, losing)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := []int{0, 10, 20, 30, 40, 50, 60, 70, 80, 90, 100}
+	for p, want := range map[float64]int{0: 0, 0.1: 10, 0.5: 50, 0.9: 90, 1: 100} {
+		got := percentile(sorted, p)
+		if got != want {
+			t.Errorf("percentile %v: got %d, want %d", p, got, want)
+		}
+	}
+	if percentile(nil, 0.5) != 0 {
+		t.Error("the percentile of no sessions")
+	}
+}
+
+func TestValidateRuin(t *testing.T) {
+	cfg := ruinConfig(t, 100, "flat")
+	cfg.Workers = 8
+	for _, test := range []struct {
+		target   int
+		sessions int64
+		ok       bool
+	}{
+		{200, 1, true},
+		{0, 1000, true},
+		{startingMoney, 1000, false},
+		{50, 1000, false},
+		{200, 0, false},
+	} {
+		err := validateRuin(cfg, test.target, test.sessions)
+		if (err == nil) != test.ok {
+			t.Errorf("target %d, %d sessions: got %v", test.target, test.sessions, err)
+		}
+	}
+}
+
+func TestSessionsEndOnRuinOrTarget(t *testing.T) {
+	cfg := ruinConfig(t, 300, "martingale")
+	target := 2 * startingMoney
+	stats := simulateSessions(cfg, target, 500)
+	if stats.Ruined+stats.Reached > stats.Sessions || len(stats.Finals) != int(stats.Sessions) {
+		t.Fatalf("%d ruined and %d reached of %d sessions with %d finals", stats.Ruined, stats.Reached, stats.Sessions, len(stats.Finals))
+	}
+	var ruined, reached int64
+	for _, final := range stats.Finals {
+		switch {
+		case final < cfg.MinBet:
+			ruined++
+		case final >= target:
+			reached++
+		}
+	}
+	if ruined != stats.Ruined || reached != stats.Reached {
+		t.Errorf("the finals show %d ruined and %d reached, the counts %d and %d", ruined, reached, stats.Ruined, stats.Reached)
+	}
+	for i, column := range stats.Trajectories {
+		if len(column) != int(stats.Sessions) {
+			t.Fatalf("checkpoint %d has %d bankrolls for %d sessions", i, len(column), stats.Sessions)
+		}
+	}
+	for _, start := range stats.Trajectories[0] {
+		if start != cfg.Bankroll {
+			t.Fatalf("a session starts with %d chips", start)
+		}
+	}
+}
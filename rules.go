@@ -0,0 +1,182 @@
+package main
+
+// ------------------- Rules ----------------------------------
+
+// The rule functions only work on gameState, so the TUI and the headless
+// frontends play exactly the same game. Messages are left to the callers.
+
+func placeBet(gs gameState, bet uint16) gameState {
+	gs.Bet = bet
+	gs.PlayerMoney -= bet
+	return gs
+}
+
+func dealRound(gs gameState) gameState {
+
+	gs.PlayerCards = gs.PlayerCards[:0]
+	gs.DealerCards = gs.DealerCards[:0]
+	gs.Turn = turnPlayer
+	gs.Phase = phasePlay
+	gs.ShowDealerHand = false
+	gs.Outcome = noOutcome
+	gs.DealerBroke = false
+
+	gs.PlayerCards, gs.DrawStack = drawOneFromStack(gs.PlayerCards, gs.DrawStack)
+	gs.DealerCards, gs.DrawStack = drawOneFromStack(gs.DealerCards, gs.DrawStack)
+	gs.PlayerCards, gs.DrawStack = drawOneFromStack(gs.PlayerCards, gs.DrawStack)
+	gs.DealerCards, gs.DrawStack = drawOneFromStack(gs.DealerCards, gs.DrawStack)
+	gs.CardsDealt += 4
+	gs.HiLoCount += hiLoTag(gs.PlayerCards[0]) + hiLoTag(gs.PlayerCards[1]) + hiLoTag(gs.DealerCards[0]) + hiLoTag(gs.DealerCards[1])
+	if gs.CardsDealt >= gs.ReshuffleThreshold {
+		gs.NeedReshuffle = true
+	}
+	gs.PlayerTotal, _ = calculateHand(gs.PlayerCards)
+	gs.DealerTotal, gs.IsSoft17 = calculateHand(gs.DealerCards)
+
+	if gs.PlayerTotal == 21 || gs.DealerTotal == 21 {
+		switch {
+		case gs.PlayerTotal == 21 && gs.DealerTotal != 21:
+			gs = settleRound(gs, naturalBlackjackWin)
+		case gs.PlayerTotal != 21 && gs.DealerTotal == 21:
+			gs = settleRound(gs, normalLoose)
+		default:
+			gs = settleRound(gs, normalDraw)
+		}
+	}
+	return gs
+}
+
+func playerHit(gs gameState, loosePayout int) gameState {
+	gs.PlayerCards, gs.DrawStack = drawOneFromStack(gs.PlayerCards, gs.DrawStack)
+	gs.CardsDealt++
+	gs.HiLoCount += hiLoTag(gs.PlayerCards[len(gs.PlayerCards)-1])
+	gs.PlayerTotal, _ = calculateHand(gs.PlayerCards)
+
+	if gs.PlayerTotal >= 22 {
+		gs = settleRound(gs, loosePayout)
+	}
+
+	if gs.CardsDealt >= gs.ReshuffleThreshold {
+		gs.NeedReshuffle = true
+	}
+	return gs
+}
+
+func dealerPlay(gs gameState, winPayout, loosePayout, drawPayout int) gameState {
+	gs.ShowDealerHand = true
+	gs.Turn = turnDealer
+
+	for gs.DealerTotal <= 16 || (gs.DealerTotal == 17 && gs.HitOnSoft17 && gs.IsSoft17) {
+		gs.DealerCards, gs.DrawStack = drawOneFromStack(gs.DealerCards, gs.DrawStack)
+		gs.CardsDealt++
+		gs.HiLoCount += hiLoTag(gs.DealerCards[len(gs.DealerCards)-1])
+		gs.DealerTotal, gs.IsSoft17 = calculateHand(gs.DealerCards)
+	}
+
+	switch {
+	case gs.DealerTotal >= 22:
+		gs = settleRound(gs, winPayout)
+	case gs.PlayerTotal > gs.DealerTotal:
+		gs = settleRound(gs, winPayout)
+	case gs.PlayerTotal < gs.DealerTotal:
+		gs = settleRound(gs, loosePayout)
+	default:
+		gs = settleRound(gs, drawPayout)
+	}
+
+	if gs.CardsDealt >= gs.ReshuffleThreshold {
+		gs.NeedReshuffle = true
+	}
+	return gs
+}
+
+func playerDouble(gs gameState) gameState {
+	gs = playerHit(gs, doubleLoose)
+	if gs.Phase == phaseEnd {
+		return gs
+	}
+	return dealerPlay(gs, doubleWin, doubleLoose, doubleDraw)
+}
+
+func playerSurrender(gs gameState) gameState {
+	return settleRound(gs, surrender)
+}
+
+func settleRound(gs gameState, outcome int) gameState {
+	gs.PlayerMoney, gs.DealerBroke = calculateMoney(gs.Payout, gs.Bet, gs.PlayerMoney, outcome)
+	gs.Outcome = outcome
+	gs.ShowDealerHand = true
+	gs.Phase = phaseEnd
+	return gs
+}
+
+func reshuffleIfNeeded(gs gameState, seed ...uint32) gameState {
+	if !gs.NeedReshuffle {
+		return gs
+	}
+	gs.DrawStack, gs.RandomSeed = newDeck(gs.NumberDecks, gs.DrawStack, seed...)
+	gs.NeedReshuffle = false
+	gs.CardsDealt = 0
+	gs.HiLoCount = 0
+	return gs
+}
+
+// hiLoTag counts a dealt card into HiLoCount, the coach and autoplay read the count from there
+// instead of dealing the shoe again from RandomSeed
+func hiLoTag(c card) int {
+	return builtinCountSystems[0].tag(c)
+}
+
+func canDouble(gs gameState) bool {
+	return gs.PlayerMoney >= gs.Bet
+}
+
+// reshuffleThreshold works in int, 255 decks at 75 % overflow uint16 before the division
+func reshuffleThreshold(numberDecks, penetration uint8) uint16 {
+	totalCards := int(numberDecks) * 52
+	return uint16(totalCards * int(penetration) / 100)
+}
+
+func payoutFromLabel(label string) uint8 {
+	switch label {
+	case payout32:
+		return 15
+	case payout75:
+		return 14
+	case payout65:
+		return 12
+	default:
+		return 0
+	}
+}
+
+func calculateMoney(payout uint8, bet, playerMoney uint16, outcome int) (uint16, bool) {
+	if playerMoney >= 65000 && (outcome == naturalBlackjackWin || outcome == normalWin) {
+		return playerMoney, true // Dealer is broke - no more winnable money
+	}
+
+	switch outcome {
+
+	case naturalBlackjackWin:
+		part := uint16(10 + payout)
+		playerMoney += bet * part / 10 // ⚠️ integer division
+	case normalWin:
+		playerMoney += bet * 2
+	case doubleWin:
+		playerMoney += bet * 3
+	case normalDraw:
+		playerMoney += bet
+	case doubleDraw:
+		playerMoney += bet
+	case normalLoose:
+		// no change to playerMoney
+		// due to starting bet. You already lost the starting bet, as such no change.
+		// If you draw you get your bet back etc.
+	case doubleLoose:
+		playerMoney -= bet
+	case surrender:
+		playerMoney += bet / 2
+	}
+
+	return playerMoney, false
+}
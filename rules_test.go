@@ -0,0 +1,87 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestCalculateMoney(t *testing.T) {
+	// the bet is paid already, 100 chips are left of 110
+	tests := []struct {
+		name    string
+		payout  uint8
+		bet     uint16
+		outcome int
+		want    uint16
+	}{
+		{"natural 3:2", 15, 10, naturalBlackjackWin, 125},
+		{"natural 7:5", 14, 10, naturalBlackjackWin, 124},
+		{"natural 6:5", 12, 50, naturalBlackjackWin, 210},
+		{"win", 15, 10, normalWin, 120},
+		{"double win", 15, 10, doubleWin, 130},
+		{"draw", 15, 10, normalDraw, 110},
+		{"double draw", 15, 10, doubleDraw, 110},
+		{"loss", 15, 10, normalLoose, 100},
+		{"double loss", 15, 10, doubleLoose, 90},
+		{"surrender", 15, 10, surrender, 105},
+	}
+	for _, test := range tests {
+		got, broke := calculateMoney(test.payout, test.bet, 100, test.outcome)
+		if got != test.want || broke {
+			t.Errorf("%s: %d chips, dealer broke %v; want %d, false", test.name, got, broke, test.want)
+		}
+	}
+}
+
+func TestDealerBroke(t *testing.T) {
+	for _, outcome := range []int{naturalBlackjackWin, normalWin} {
+		got, broke := calculateMoney(15, 50, 65000, outcome)
+		if got != 65000 || !broke {
+			t.Errorf("outcome %d at 65000 chips: %d chips, dealer broke %v; want 65000, true", outcome, got, broke)
+		}
+	}
+	got, broke := calculateMoney(15, 50, 64999, normalWin)
+	if got != 65099 || broke {
+		t.Errorf("a win at 64999 chips: %d chips, dealer broke %v; want 65099, false", got, broke)
+	}
+	got, broke = calculateMoney(15, 50, 65000, normalDraw)
+	if got != 65050 || broke {
+		t.Errorf("a draw at 65000 chips: %d chips, dealer broke %v; want the bet back", got, broke)
+	}
+
+	gs := settleRound(gameState{Phase: phasePlay, Payout: 15, Bet: 50, PlayerMoney: 65000}, normalWin)
+	if !gs.DealerBroke || gs.PlayerMoney != 65000 || gs.Outcome != normalWin || gs.Phase != phaseEnd || !gs.ShowDealerHand {
+		t.Errorf("settleRound with a broke dealer: %+v", gs)
+	}
+	gs.NumberDecks = 1
+	gs.DrawStack, gs.RandomSeed = newDeck(gs.NumberDecks, make([]card, 0, 52), 1)
+	gs = dealRound(placeBet(gs, 10))
+	if gs.DealerBroke {
+		t.Error("the next deal still has a broke dealer")
+	}
+}
+
+func TestSettleRound(t *testing.T) {
+	gs := placeBet(gameState{Phase: phasePlay, Payout: 15, PlayerMoney: 110}, 10)
+	gs = settleRound(gs, surrender)
+	if gs.PlayerMoney != 105 || gs.Outcome != surrender || gs.Phase != phaseEnd || !gs.ShowDealerHand || gs.DealerBroke {
+		t.Errorf("settleRound of a surrender: %+v", gs)
+	}
+}
+
+func TestReshuffleThresholdOfLargeShoes(t *testing.T) {
+	tests := []struct {
+		decks, pen uint8
+		want       uint16
+	}{
+		{1, 50, 26},
+		{6, 75, 234},
+		{17, 75, 663},
+		{255, 75, 9945},
+		{255, 0, 0},
+	}
+	for _, test := range tests {
+		if got := reshuffleThreshold(test.decks, test.pen); got != test.want {
+			t.Errorf("%d decks at %d %%: %d, want %d", test.decks, test.pen, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"strconv"
+)
+
+// ------------------- Session Statistics ---------------------
+
+// withSessionRecord counts the round once the selection has finished it. Every way a round
+// ends (a natural on the deal, a bust, a stand, a double or a surrender) passes through here.
+func withSessionRecord(model tea.Model, cmd tea.Cmd) (tea.Model, tea.Cmd) {
+	m, ok := model.(blackjackModel)
+	if !ok || m.Game.Phase != phaseEnd || m.Session.Recorded {
+		return model, cmd
+	}
+	m.Session = m.Session.record(m.Game)
+	autosave(m)
+	return m, cmd
+}
+
+// record maps the outcome constants to the tallies, the net comes from the money
+// before the bet so a broke dealer counts what was really paid
+func (s sessionStats) record(gs gameState) sessionStats {
+	s.Recorded = true
+	s.Hands++
+	s.Wagered += int(gs.Bet)
+	net := int(gs.PlayerMoney) - int(s.MoneyBefore)
+	s.Net += net
+	s.BiggestWin = max(s.BiggestWin, net)
+
+	switch gs.Outcome {
+	case naturalBlackjackWin:
+		s.Naturals++
+	case doubleWin, doubleLoose, doubleDraw:
+		s.Doubles++
+	case surrender:
+		s.Surrenders++
+	}
+	if gs.PlayerTotal > 21 {
+		s.Busts++
+	}
+
+	switch gs.Outcome {
+	case naturalBlackjackWin, normalWin, doubleWin:
+		s.Wins++
+		s.Streak = max(s.Streak, 0) + 1
+		s.LongestWinStreak = max(s.LongestWinStreak, s.Streak)
+	case normalLoose, doubleLoose, surrender:
+		s.Losses++
+		s.Streak = min(s.Streak, 0) - 1
+		s.LongestLossStreak = max(s.LongestLossStreak, -s.Streak)
+	case normalDraw, doubleDraw:
+		s.Pushes++ // a push keeps the streak going
+	}
+	return s
+}
+
+func (t handTotals) averageBet() float64 {
+	if t.Hands == 0 {
+		return 0
+	}
+	return float64(t.Wagered) / float64(t.Hands)
+}
+
+// add sums the tallies, the biggest win and the streaks stay the best of a single session
+func (t handTotals) add(other handTotals) handTotals {
+	t.Hands += other.Hands
+	t.Wins += other.Wins
+	t.Losses += other.Losses
+	t.Pushes += other.Pushes
+	t.Naturals += other.Naturals
+	t.Busts += other.Busts
+	t.Doubles += other.Doubles
+	t.Surrenders += other.Surrenders
+	t.Net += other.Net
+	t.Wagered += other.Wagered
+	t.BiggestWin = max(t.BiggestWin, other.BiggestWin)
+	t.LongestWinStreak = max(t.LongestWinStreak, other.LongestWinStreak)
+	t.LongestLossStreak = max(t.LongestLossStreak, other.LongestLossStreak)
+	return t
+}
+
+func sessionLines(m blackjackModel) []string {
+	return append([]string{m.UiText.StatsTitle}, totalsLines(m.UiText, m.Session.handTotals)...)
+}
+
+func totalsLines(text uiText, t handTotals) []string {
+	return []string{
+		"  " + text.StatsHands + strconv.Itoa(t.Hands),
+		"  " + text.StatsResults + strconv.Itoa(t.Wins) + " / " + strconv.Itoa(t.Losses) + " / " + strconv.Itoa(t.Pushes),
+		"  " + text.StatsSpecial + strconv.Itoa(t.Naturals) + " / " + strconv.Itoa(t.Busts) + " / " +
+			strconv.Itoa(t.Doubles) + " / " + strconv.Itoa(t.Surrenders),
+		"  " + text.StatsNet + signedChips(t.Net),
+		"  " + text.StatsBiggestWin + strconv.Itoa(t.BiggestWin),
+		"  " + text.StatsStreaks + strconv.Itoa(t.LongestWinStreak) + " / " + strconv.Itoa(t.LongestLossStreak),
+		"  " + text.StatsAverageBet + strconv.FormatFloat(t.averageBet(), 'f', 1, 64),
+	}
+}
+
+func signedChips(chips int) string {
+	if chips > 0 {
+		return "+" + strconv.Itoa(chips)
+	}
+	return strconv.Itoa(chips)
+}
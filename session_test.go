@@ -0,0 +1,77 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSessionRecord(t *testing.T) {
+	rounds := []struct {
+		outcome     int
+		bet, net    int
+		playerTotal int
+	}{
+		{naturalBlackjackWin, 10, 15, 21},
+		{normalWin, 20, 20, 20},
+		{normalDraw, 10, 0, 18}, // a push keeps the streak going
+		{doubleWin, 20, 40, 19},
+		{normalLoose, 10, -10, 24},
+		{surrender, 20, -10, 16},
+		{doubleLoose, 10, -20, 17},
+		{doubleDraw, 10, 0, 20},
+	}
+	var s sessionStats
+	money := 100
+	for _, round := range rounds {
+		s.MoneyBefore = uint16(money)
+		money += round.net
+		s = s.record(gameState{Outcome: round.outcome, Bet: uint16(round.bet), PlayerMoney: uint16(money), PlayerTotal: round.playerTotal})
+	}
+
+	want := handTotals{
+		Hands: 8, Wins: 3, Losses: 3, Pushes: 2, Naturals: 1, Busts: 1, Doubles: 3, Surrenders: 1,
+		Net: 35, BiggestWin: 40, Wagered: 110, LongestWinStreak: 3, LongestLossStreak: 3,
+	}
+	if s.handTotals != want {
+		t.Errorf("got %+v, want %+v", s.handTotals, want)
+	}
+	if s.averageBet() != 13.75 {
+		t.Errorf("average bet %v", s.averageBet())
+	}
+
+	both := s.handTotals.add(handTotals{Hands: 2, Wins: 2, Net: 60, BiggestWin: 50, Wagered: 20, LongestWinStreak: 2})
+	if both.Hands != 10 || both.Net != 95 || both.BiggestWin != 50 || both.LongestWinStreak != 3 {
+		t.Errorf("two sessions add up to %+v", both)
+	}
+}
+
+func TestSessionRecordedOnce(t *testing.T) {
+	resetStorage(t)
+	m := tableModel(t, gameState{Phase: phaseEnd, Outcome: normalWin, Bet: 10, PlayerMoney: 110})
+	m.Session.MoneyBefore = 100
+	model, _ := withSessionRecord(m, nil)
+	model, _ = withSessionRecord(model, nil)
+	m = model.(blackjackModel)
+	if m.Session.Hands != 1 || m.Session.Net != 10 {
+		t.Errorf("%d hands for %d chips after one round", m.Session.Hands, m.Session.Net)
+	}
+}
+
+func TestGameOverShowsSession(t *testing.T) {
+	resetStorage(t)
+	m := tableModel(t, gameState{Phase: phaseEnd, Outcome: normalLoose, Bet: 10, PlayerMoney: 0})
+	m.UiState.WindowWidth, m.UiState.WindowHeight = 80, 30
+	m.Session.MoneyBefore = 10
+	model, _ := withSessionRecord(m, nil)
+	model, _ = handleSelection(model.(blackjackModel), m.UiText.OptionRestart)
+	over, ok := model.(gameOverModel)
+	if !ok {
+		t.Fatalf("broke at the restart, got %T", model)
+	}
+	view := over.View()
+	for _, want := range []string{m.UiText.StatsTitle, m.UiText.StatsHands + "1", m.UiText.StatsNet + "-10"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("the game over screen lacks %q", want)
+		}
+	}
+}
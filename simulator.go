@@ -0,0 +1,427 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ------------------- Simulate Command -----------------------
+
+func runSimulate(args []string) int {
+	flags := flag.NewFlagSet(commandSimulate, flag.ContinueOnError)
+	f := defineSimFlags(flags, 1000000, "number of rounds to play", 500, 10000)
+	policy := flags.String("policy", "flat", "bet policy: "+strings.Join(betPolicyNames(), ", ")+" or all to compare them")
+	err := flags.Parse(args)
+	if err != nil {
+		return 2
+	}
+
+	policyNames := []string{*policy}
+	if *policy == "all" {
+		policyNames = betPolicyNames()
+	}
+	configs := make([]simConfig, 0, len(policyNames))
+	for _, policyName := range policyNames {
+		cfg, err := f.config(policyName)
+		if err != nil {
+			fmt.Println("simulate: " + err.Error())
+			return 2
+		}
+		configs = append(configs, cfg)
+	}
+
+	if len(configs) == 1 {
+		start := time.Now()
+		stats := simulate(configs[0])
+		printSimReport(os.Stdout, configs[0], stats, time.Since(start))
+		return 0
+	}
+
+	// every policy plays the same shoes, only the bets differ
+	results := make([]simStats, len(configs))
+	for i := range configs {
+		configs[i].Seed = configs[0].Seed
+		results[i] = simulate(configs[i])
+	}
+	printPolicyComparison(os.Stdout, configs, results)
+	return 0
+}
+
+// defineSimFlags registers the table, strategy and betting flags that simulate and ruin share
+func defineSimFlags(flags *flag.FlagSet, rounds int64, roundsUsage string, maxBet, bankroll int) simFlags {
+	return simFlags{
+		Rounds:       flags.Int64("rounds", rounds, roundsUsage),
+		Payout:       flags.String("payout", payout32, "blackjack payout: "+payout32+", "+payout75+" or "+payout65),
+		HitOnSoft17:  flags.Bool("h17", false, "dealer hits on soft 17"),
+		Decks:        flags.Int("decks", 6, "number of decks (1-"+strconv.Itoa(maxDecks)+")"),
+		Pen:          flags.Int("pen", 75, "penetration in percent: 0, 25, 50 or 75 (75 needs at least 2 decks)"),
+		StrategyName: flags.String("strategy", "basic", "playing strategy: "+strings.Join(strategyNames(), ", ")),
+		Bet:          flags.Int("bet", 10, "betting unit, the flat bet and the base of every other policy"),
+		MinBet:       flags.Int("min", 10, "table minimum"),
+		MaxBet:       flags.Int("max", maxBet, "table maximum"),
+		Bankroll:     flags.Int("bankroll", bankroll, "bankroll in chips, refilled after every ruin"),
+		Spread:       flags.Int("spread", 8, "units at the top of the ramp policy"),
+		Kelly:        flags.Float64("kelly", 0.5, "Kelly fraction of the kelly policy"),
+		Seed:         flags.Uint64("seed", 0, "master seed, 0 picks one from the clock"),
+		Workers:      flags.Int("workers", runtime.GOMAXPROCS(0), "number of parallel workers, results are reproducible for the same seed and worker count"),
+	}
+}
+
+func (f simFlags) config(policyName string) (simConfig, error) {
+	cfg, err := newSimConfig(*f.Rounds, *f.Payout, *f.HitOnSoft17, *f.Decks, *f.Pen, *f.StrategyName, *f.Bet, *f.Seed, *f.Workers)
+	if err != nil {
+		return simConfig{}, err
+	}
+	return withBetPolicy(cfg, policyName, *f.MinBet, *f.MaxBet, *f.Bankroll, *f.Spread, *f.Kelly)
+}
+
+func newSimConfig(rounds int64, payout string, h17 bool, decks, pen int, strategyName string, bet int, seed uint64, workers int) (simConfig, error) {
+	if rounds < 1 {
+		return simConfig{}, fmt.Errorf("rounds must be at least 1, got %d", rounds)
+	}
+	if workers < 1 || int64(workers) > rounds {
+		return simConfig{}, fmt.Errorf("workers must be between 1 and the number of rounds, got %d", workers)
+	}
+	payoutValue := payoutFromLabel(payout)
+	if payoutValue == 0 {
+		return simConfig{}, fmt.Errorf("unknown payout %q", payout)
+	}
+	if decks < 1 || decks > maxDecks {
+		return simConfig{}, fmt.Errorf("decks must be between 1 and %d, got %d", maxDecks, decks)
+	}
+	switch {
+	case pen != 0 && pen != 25 && pen != 50 && pen != 75:
+		return simConfig{}, fmt.Errorf("penetration must be 0, 25, 50 or 75, got %d", pen)
+	case pen == 75 && decks < 2:
+		return simConfig{}, fmt.Errorf("75 %% penetration needs at least 2 decks")
+	}
+	strat, ok := strategies[strategyName]
+	if !ok {
+		return simConfig{}, fmt.Errorf("unknown strategy %q", strategyName)
+	}
+	if bet < 10 || bet > simBankroll/4 || bet%10 != 0 {
+		return simConfig{}, fmt.Errorf("bet must be a multiple of 10 between 10 and %d, got %d", simBankroll/4, bet)
+	}
+	if seed == 0 {
+		seed = uint64(time.Now().UnixNano())
+	}
+	return simConfig{
+		Strategy:     strat,
+		StrategyName: strategyName,
+		Rounds:       rounds,
+		Seed:         seed,
+		Workers:      workers,
+		Bet:          uint16(bet),
+		NumberDecks:  uint8(decks),
+		Payout:       payoutValue,
+		Penetration:  uint8(pen),
+		HitOnSoft17:  h17,
+		Policy:       flatPolicy,
+		PolicyName:   "flat",
+		MinBet:       bet,
+		MaxBet:       bet,
+		Bankroll:     simBankroll,
+	}, nil
+}
+
+func withBetPolicy(cfg simConfig, policyName string, minBet, maxBet, bankroll, spread int, kellyFraction float64) (simConfig, error) {
+	policy, ok := betPolicies[policyName]
+	if !ok {
+		return simConfig{}, fmt.Errorf("unknown bet policy %q", policyName)
+	}
+	switch {
+	case minBet < 10 || minBet%10 != 0:
+		return simConfig{}, fmt.Errorf("table minimum must be a multiple of 10 and at least 10, got %d", minBet)
+	case maxBet < minBet || maxBet > simBankroll/4 || maxBet%10 != 0:
+		return simConfig{}, fmt.Errorf("table maximum must be a multiple of 10 between %d and %d, got %d", minBet, simBankroll/4, maxBet)
+	case int(cfg.Bet) < minBet || int(cfg.Bet) > maxBet:
+		return simConfig{}, fmt.Errorf("bet must be within the table limits %d-%d, got %d", minBet, maxBet, cfg.Bet)
+	case bankroll < minBet:
+		return simConfig{}, fmt.Errorf("bankroll must cover the table minimum, got %d", bankroll)
+	case spread < 1:
+		return simConfig{}, fmt.Errorf("spread must be at least 1, got %d", spread)
+	case kellyFraction <= 0 || kellyFraction > 1:
+		return simConfig{}, fmt.Errorf("Kelly fraction must be above 0 and at most 1, got %g", kellyFraction)
+	}
+	cfg.Policy = policy
+	cfg.PolicyName = policyName
+	cfg.MinBet = minBet
+	cfg.MaxBet = maxBet
+	cfg.Bankroll = bankroll
+	cfg.Spread = spread
+	cfg.KellyFraction = kellyFraction
+	return cfg, nil
+}
+
+// simulate splits the rounds into chunks of simChunkRounds and cfg.Workers goroutines take
+// every cfg.Workers-th chunk. Every chunk builds its own table from the sub-seed of its index,
+// so no DrawStack is ever shared, and the chunks are merged in their order, which keeps the
+// results bit-identical for the same seed whatever the number of workers.
+func simulate(cfg simConfig) simStats {
+	chunks := int((cfg.Rounds + simChunkRounds - 1) / simChunkRounds)
+	results := make([]simStats, chunks)
+	var wg sync.WaitGroup
+	for worker := range min(cfg.Workers, chunks) {
+		wg.Go(func() {
+			for chunk := worker; chunk < chunks; chunk += cfg.Workers {
+				rounds := min(simChunkRounds, cfg.Rounds-int64(chunk)*simChunkRounds)
+				results[chunk] = simulateChunk(cfg, workerSeed(cfg.Seed, chunk), rounds)
+			}
+		})
+	}
+	wg.Wait()
+
+	var stats simStats
+	for _, result := range results {
+		stats = stats.merge(result)
+	}
+	return stats
+}
+
+func simulateChunk(cfg simConfig, seed uint64, rounds int64) simStats {
+	rng := rand.New(rand.NewPCG(seed, simStream))
+	gs := newSimTable(cfg, rng)
+
+	b := newBettor(cfg.Policy, int(cfg.Bet), cfg.MinBet, cfg.MaxBet, cfg.Spread, cfg.KellyFraction,
+		baselineEdge(cfg.Payout, cfg.HitOnSoft17, cfg.NumberDecks))
+	bankroll := cfg.Bankroll
+
+	var stats simStats
+	var net int
+	for i := int64(0); i < rounds; i++ {
+		if bankroll < cfg.MinBet {
+			stats.Ruins++
+			bankroll = cfg.Bankroll
+			b.restart()
+		}
+		gs = reshuffleIfNeeded(gs, rng.Uint32())
+		bet := b.next(gs, bankroll)
+		gs, net = simRound(gs, cfg, uint16(bet), bankroll, b.Running)
+		bankroll += net
+		b.record(gs, bet, net)
+		stats.add(net, bet, gs.Outcome)
+	}
+	return stats
+}
+
+// workerSeed derives the sub-seed of a worker or a chunk from the master seed with splitmix64
+func workerSeed(master uint64, worker int) uint64 {
+	z := master + uint64(worker+1)*simStream
+	z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+	z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+	return z ^ (z >> 31)
+}
+
+func newSimTable(cfg simConfig, rng *rand.Rand) gameState {
+	totalCards := uint16(cfg.NumberDecks) * 52
+	gs := gameState{
+		Phase:              phaseBet,
+		NumberDecks:        cfg.NumberDecks,
+		Payout:             cfg.Payout,
+		HitOnSoft17:        cfg.HitOnSoft17,
+		ReshuffleThreshold: reshuffleThreshold(cfg.NumberDecks, cfg.Penetration),
+		PlayerCards:        make([]card, 0, 22), // 22xA
+		DealerCards:        make([]card, 0, 13), // 7xA + 1x5 + 5xA
+	}
+	gs.DrawStack, gs.RandomSeed = newDeck(cfg.NumberDecks, make([]card, 0, totalCards), rng.Uint32())
+	return gs
+}
+
+// simRound plays one round the same way the TUI does between two "Restart / Bet"
+// selections and returns the chips won or lost, including the bet itself.
+// The bankroll limits doubles like PlayerMoney does in the TUI, the Hi-Lo running count
+// before the round feeds the true count of the index strategies.
+func simRound(gs gameState, cfg simConfig, bet uint16, bankroll, running int) (gameState, int) {
+	money := uint16(min(bankroll, simBankroll))
+	gs.PlayerMoney = money
+	gs = placeBet(gs, bet)
+	gs = dealRound(gs)
+
+	for gs.Phase == phasePlay {
+		v := viewOf(gs)
+		v.TrueCount = handTrueCount(running, gs)
+		action := cfg.Strategy(v)
+		if !legalAction(gs, action) {
+			action = actionHit // a double that isn't allowed is played as a hit
+		}
+		gs = applyAction(gs, action)
+	}
+	return gs, int(gs.PlayerMoney) - int(money)
+}
+
+// ------------------- Streaming Statistics -------------------
+
+// add uses Welford's algorithm, so billions of rounds don't lose precision
+func (s *simStats) add(netChips int, bet int, outcome int) {
+	s.Rounds++
+	s.Outcomes[outcome]++
+	s.NetChips += int64(netChips)
+	s.Wagered += int64(bet)
+	s.Histogram[histogramOffset+netChips*10/bet]++ // bets are multiples of 10, so this is exact
+	net := float64(netChips) / float64(bet)
+	delta := net - s.Mean
+	s.Mean += delta / float64(s.Rounds)
+	s.M2 += delta * (net - s.Mean)
+	chipDelta := float64(netChips) - s.ChipMean
+	s.ChipMean += chipDelta / float64(s.Rounds)
+	s.ChipM2 += chipDelta * (float64(netChips) - s.ChipMean)
+}
+
+// merge combines two workers with Chan et al.'s parallel variance formula,
+// counts and histograms are plain sums
+func (s simStats) merge(o simStats) simStats {
+	if o.Rounds == 0 {
+		return s
+	}
+	if s.Rounds == 0 {
+		return o
+	}
+	rounds := s.Rounds + o.Rounds
+	delta := o.Mean - s.Mean
+	chipDelta := o.ChipMean - s.ChipMean
+	weight := float64(s.Rounds) * float64(o.Rounds) / float64(rounds)
+	merged := simStats{
+		Rounds:   rounds,
+		NetChips: s.NetChips + o.NetChips,
+		Wagered:  s.Wagered + o.Wagered,
+		Ruins:    s.Ruins + o.Ruins,
+		Mean:     s.Mean + delta*float64(o.Rounds)/float64(rounds),
+		M2:       s.M2 + o.M2 + delta*delta*weight,
+		ChipMean: s.ChipMean + chipDelta*float64(o.Rounds)/float64(rounds),
+		ChipM2:   s.ChipM2 + o.ChipM2 + chipDelta*chipDelta*weight,
+	}
+	for i := range merged.Outcomes {
+		merged.Outcomes[i] = s.Outcomes[i] + o.Outcomes[i]
+	}
+	for i := range merged.Histogram {
+		merged.Histogram[i] = s.Histogram[i] + o.Histogram[i]
+	}
+	return merged
+}
+
+func (s simStats) variance() float64 {
+	if s.Rounds < 2 {
+		return 0
+	}
+	return s.M2 / float64(s.Rounds-1)
+}
+
+func (s simStats) chipVariance() float64 {
+	if s.Rounds < 2 {
+		return 0
+	}
+	return s.ChipM2 / float64(s.Rounds-1)
+}
+
+// ------------------- Report ---------------------------------
+
+func printSimReport(w io.Writer, cfg simConfig, stats simStats, elapsed time.Duration) {
+	stdDev := math.Sqrt(stats.variance())
+	margin := 1.96 * stdDev / math.Sqrt(float64(stats.Rounds))
+
+	_, _ = fmt.Fprintf(w, "Rules:          %s\n", rulesSummary(cfg.Payout, cfg.HitOnSoft17, cfg.NumberDecks, cfg.Penetration))
+	_, _ = fmt.Fprintf(w, "Strategy:       %s\n", cfg.StrategyName)
+	_, _ = fmt.Fprintf(w, "Bet:            %s\n", betSummary(cfg))
+	_, _ = fmt.Fprintf(w, "Seed:           %d (%d workers)\n", cfg.Seed, cfg.Workers)
+	_, _ = fmt.Fprintf(w, "Rounds:         %d (%s)\n", stats.Rounds, elapsed.Round(time.Millisecond))
+	_, _ = fmt.Fprintf(w, "House edge:     %.3f %% ± %.3f %% (95 %%)\n", -100*stats.Mean, 100*margin)
+	_, _ = fmt.Fprintf(w, "Std deviation:  %.4f bets per round\n", stdDev)
+	_, _ = fmt.Fprintf(w, "Net result:     %d chips\n", stats.NetChips)
+	if cfg.PolicyName != "flat" {
+		chipStdDev := math.Sqrt(stats.chipVariance())
+		chipMargin := 1.96 * chipStdDev / math.Sqrt(float64(stats.Rounds))
+		_, _ = fmt.Fprintf(w, "Average bet:    %.1f chips\n", float64(stats.Wagered)/float64(stats.Rounds))
+		_, _ = fmt.Fprintf(w, "Win rate:       %.2f ± %.2f chips per 100 rounds (95 %%)\n", 100*stats.ChipMean, 100*chipMargin)
+		_, _ = fmt.Fprintf(w, "Chip std dev:   %.1f chips per round\n", chipStdDev)
+		_, _ = fmt.Fprintf(w, "Return:         %.3f %% of the initial bets\n", 100*float64(stats.NetChips)/float64(stats.Wagered))
+	}
+	_, _ = fmt.Fprintf(w, "Ruins:          %d (bankroll %d)\n", stats.Ruins, cfg.Bankroll)
+	_, _ = fmt.Fprintln(w, "Outcomes:")
+	for outcome := naturalBlackjackWin; outcome <= surrender; outcome++ {
+		frequency := float64(stats.Outcomes[outcome]) / float64(stats.Rounds)
+		_, _ = fmt.Fprintf(w, "  %-22s %8.4f %%\n", outcomeNames[outcome], 100*frequency)
+	}
+	_, _ = fmt.Fprintln(w, "Net per round (bets):")
+	for i, count := range stats.Histogram {
+		if count == 0 {
+			continue
+		}
+		frequency := float64(count) / float64(stats.Rounds)
+		_, _ = fmt.Fprintf(w, "  %+5.1f %8.4f %% %s\n", float64(i-histogramOffset)/10, 100*frequency, histogramBar(frequency))
+	}
+}
+
+// printPolicyComparison puts the policies side by side, the house edge per bet hardly
+// moves, what changes is the size of the bets and with it the swings and the ruins
+func printPolicyComparison(w io.Writer, configs []simConfig, results []simStats) {
+	cfg := configs[0]
+	_, _ = fmt.Fprintf(w, "Rules:     %s\n", rulesSummary(cfg.Payout, cfg.HitOnSoft17, cfg.NumberDecks, cfg.Penetration))
+	_, _ = fmt.Fprintf(w, "Strategy:  %s\n", cfg.StrategyName)
+	_, _ = fmt.Fprintf(w, "Table:     %d-%d, unit %d, bankroll %d\n", cfg.MinBet, cfg.MaxBet, cfg.Bet, cfg.Bankroll)
+	_, _ = fmt.Fprintf(w, "Seed:      %d (%d workers), %d rounds per policy\n\n", cfg.Seed, cfg.Workers, cfg.Rounds)
+	_, _ = fmt.Fprintf(w, "%-11s %9s %14s %12s %10s %8s\n", "Policy", "Avg bet", "Chips/100 rds", "Std dev/rd", "Return", "Ruins")
+	for i, stats := range results {
+		_, _ = fmt.Fprintf(w, "%-11s %9.1f %14.2f %12.1f %9.3f%% %8d\n", configs[i].PolicyName,
+			float64(stats.Wagered)/float64(stats.Rounds), 100*stats.ChipMean, math.Sqrt(stats.chipVariance()),
+			100*float64(stats.NetChips)/float64(stats.Wagered), stats.Ruins)
+	}
+}
+
+func betSummary(cfg simConfig) string {
+	limits := fmt.Sprintf(", table %d-%d", cfg.MinBet, cfg.MaxBet)
+	switch cfg.PolicyName {
+	case "flat":
+		return "flat " + strconv.Itoa(int(cfg.Bet))
+	case "ramp":
+		return fmt.Sprintf("Hi-Lo ramp 1-%d x %d", cfg.Spread, cfg.Bet) + limits
+	case "kelly":
+		return fmt.Sprintf("%g Kelly on Hi-Lo edge", cfg.KellyFraction) + limits
+	default:
+		return cfg.PolicyName + " from " + strconv.Itoa(int(cfg.Bet)) + limits
+	}
+}
+
+func histogramBar(frequency float64) string {
+	return strings.Repeat("#", int(math.Round(frequency*50)))
+}
+
+func rulesSummary(payout uint8, hitOnSoft17 bool, numberDecks, penetration uint8) string {
+	dealerRule := "S17"
+	if hitOnSoft17 {
+		dealerRule = "H17"
+	}
+	return payoutLabel(payout) + ", " + dealerRule + ", " + strconv.Itoa(int(numberDecks)) + " decks, " +
+		strconv.Itoa(int(penetration)) + " % penetration"
+}
+
+func payoutLabel(payout uint8) string {
+	switch payout {
+	case 15:
+		return payout32
+	case 14:
+		return payout75
+	case 12:
+		return payout65
+	default:
+		return "?"
+	}
+}
+
+func strategyNames() []string {
+	names := make([]string, 0, len(strategies))
+	for name := range strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
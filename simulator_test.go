@@ -0,0 +1,76 @@
+package main
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestSimulateIsSameForAnyWorkerCount(t *testing.T) {
+	cfg, err := newSimConfig(3*simChunkRounds+123, payout32, false, 6, 75, "basic", 10, 42, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := simulate(cfg)
+	if want.Rounds != cfg.Rounds {
+		t.Fatalf("played %d of %d rounds", want.Rounds, cfg.Rounds)
+	}
+	for _, workers := range []int{2, 3, 4, 7} {
+		cfg.Workers = workers
+		got := simulate(cfg)
+		if got != want {
+			t.Errorf("%d workers: mean %v, variance %v, net %d; 1 worker: mean %v, variance %v, net %d",
+				workers, got.Mean, got.variance(), got.NetChips, want.Mean, want.variance(), want.NetChips)
+		}
+	}
+}
+
+func TestMergeMatchesSinglePass(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	outcomes := []int{normalWin, normalLoose, normalDraw, doubleWin, doubleLoose, naturalBlackjackWin, surrender}
+	netOf := func(outcome, bet int) int {
+		switch outcome {
+		case normalWin:
+			return bet
+		case doubleWin:
+			return 2 * bet
+		case normalLoose:
+			return -bet
+		case doubleLoose:
+			return -2 * bet
+		case naturalBlackjackWin:
+			return bet * 3 / 2
+		case surrender:
+			return -bet / 2
+		}
+		return 0
+	}
+
+	var single simStats
+	parts := make([]simStats, 5)
+	for i := range 10000 {
+		outcome := outcomes[rng.IntN(len(outcomes))]
+		bet := 10 * (1 + rng.IntN(5))
+		netChips := netOf(outcome, bet)
+		single.add(netChips, bet, outcome)
+		parts[i*len(parts)/10000].add(netChips, bet, outcome)
+	}
+	var merged simStats
+	for _, part := range parts {
+		merged = merged.merge(part)
+	}
+
+	if merged.Rounds != single.Rounds || merged.NetChips != single.NetChips || merged.Wagered != single.Wagered ||
+		merged.Outcomes != single.Outcomes || merged.Histogram != single.Histogram {
+		t.Fatal("the counts of the merge differ from a single pass")
+	}
+	near := func(name string, got, want float64) {
+		if math.Abs(got-want) > 1e-9*math.Max(1, math.Abs(want)) {
+			t.Errorf("%s: merged %v, single pass %v", name, got, want)
+		}
+	}
+	near("mean", merged.Mean, single.Mean)
+	near("variance", merged.variance(), single.variance())
+	near("chip mean", merged.ChipMean, single.ChipMean)
+	near("chip variance", merged.chipVariance(), single.chipVariance())
+}
@@ -0,0 +1,341 @@
+package main
+
+import (
+	"errors"
+	tea "github.com/charmbracelet/bubbletea"
+	"log"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ------------------- Save Slots -----------------------------
+
+// Every line of save.txt is one slot: "timestamp code", a tab and the name of the slot.
+// Older versions appended a line per save and wrote no name, their lines are slots named
+// by their timestamp. Saving replaces the line of the slot and moves it to the top.
+
+func parseSlotLine(line string) saveSlot {
+	save, name, _ := strings.Cut(line, tabString)
+	timestamp, code, _ := strings.Cut(save, singleSpaceString)
+	if name == emptyString {
+		name = timestamp
+	}
+	return saveSlot{Name: name, Time: timestamp, Code: code}
+}
+
+func (s saveSlot) line() string {
+	return s.Time + singleSpaceString + s.Code + tabString + s.Name
+}
+
+// saveString is the line without its name, the form decodeGameState reads
+func (s saveSlot) saveString() string {
+	return s.Time + singleSpaceString + s.Code
+}
+
+// readSlots parses the slots without decoding their codes, enough to replace or name a slot
+func readSlots() []saveSlot {
+	lines, err := loadSaveFile()
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Println(err)
+	}
+	slots := make([]saveSlot, 0, len(lines))
+	for _, line := range lines {
+		slot := parseSlotLine(line)
+		slot.Name = uniqueSlotName(slots, slot.Name) // two legacy saves of the same second
+		slots = append(slots, slot)
+	}
+	return slots
+}
+
+// loadSlots reads the slots and decodes each once for the money and the rules shown in the list
+func loadSlots() []saveSlot {
+	slots := readSlots()
+	for i, slot := range slots {
+		gs, _, err := decodeGameState(slot.saveString())
+		if err != nil {
+			slots[i].Err = err
+			continue
+		}
+		slots[i].Money = int(gs.PlayerMoney)
+		slots[i].Rules = rulesSummary(gs.Payout, gs.HitOnSoft17, gs.NumberDecks, penetrationOf(gs))
+	}
+	return slots
+}
+
+// writeSlots replaces save.txt, the content before goes to the backups first
+func writeSlots(slots []saveSlot) error {
+	filename := store.path(saveFile)
+	existingContent, err := os.ReadFile(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(existingContent) > 0 {
+		err = rotateSaveBackups(filename, existingContent)
+		if err != nil {
+			log.Printf("Error rotating backups: %v\n", err) // the save itself still goes through
+		}
+	}
+	return writeSlotLines(slots)
+}
+
+// writeSlotLines replaces save.txt without touching the backups. The write is atomic,
+// so save.txt is never torn, only the backups of an older state are kept.
+func writeSlotLines(slots []saveSlot) error {
+	var sb strings.Builder
+	for _, slot := range slots {
+		sb.WriteString(slot.line())
+		sb.WriteByte(newlineRune)
+	}
+	return writeFileAtomic(store.path(saveFile), []byte(sb.String()))
+}
+
+// encodeSave is the code of the game, signed when saveMode asks for it
+func encodeSave(gs gameState) (string, error) {
+	if saveMode == saveModeMAC {
+		key, err := installSecret()
+		if err != nil {
+			return emptyString, err
+		}
+		return gs.encodeWithMAC(key), nil
+	}
+	return gs.encodeWithChecksum()
+}
+
+// storeSlot saves the game into the slot of that name and moves the slot to the top
+func storeSlot(name string, gs gameState, now time.Time) error {
+	code, err := encodeSave(gs)
+	if err != nil {
+		return err
+	}
+	slots := slices.DeleteFunc(readSlots(), func(s saveSlot) bool { return s.Name == name })
+	slot := saveSlot{Name: name, Time: now.Format(saveTimeLayout), Code: code}
+	return writeSlots(append([]saveSlot{slot}, slots...))
+}
+
+// autosave keeps the last finished round in autosaveSlot and moves the slot the game was
+// loaded from or saved into forward with it. A hand saved in progress is replaced by its
+// outcome, so it can't be loaded again to replay the same cards.
+func autosave(m blackjackModel) {
+	err := storeRound(m.Slot, m.Game, time.Now())
+	if err != nil {
+		log.Printf("Error writing the autosave: %v\n", err)
+	}
+}
+
+// storeRound writes autosaveSlot and, when it's on disk already, the slot of that name in one go.
+// A new game that was never saved gets no slot of its own. It runs after every round, so it
+// skips the backups: they keep the slots from before the last save, rename, delete or import
+// instead of a few rounds of the same game.
+func storeRound(name string, gs gameState, now time.Time) error {
+	code, err := encodeSave(gs)
+	if err != nil {
+		return err
+	}
+	timestamp := now.Format(saveTimeLayout)
+	slots := readSlots()
+	top := []saveSlot{{Name: autosaveSlot, Time: timestamp, Code: code}}
+	if name != autosaveSlot && slices.ContainsFunc(slots, func(s saveSlot) bool { return s.Name == name }) {
+		top = append(top, saveSlot{Name: name, Time: timestamp, Code: code})
+	}
+	slots = slices.DeleteFunc(slots, func(s saveSlot) bool { return s.Name == autosaveSlot || s.Name == name })
+	return writeSlotLines(append(top, slots...))
+}
+
+// uniqueSlotName numbers a name that is taken already: "Player", "Player 2", "Player 3", ...
+func uniqueSlotName(slots []saveSlot, name string) string {
+	taken := func(candidate string) bool {
+		return slices.ContainsFunc(slots, func(s saveSlot) bool { return s.Name == candidate })
+	}
+	candidate := name
+	for i := 2; taken(candidate); i++ {
+		candidate = name + singleSpaceString + strconv.Itoa(i)
+	}
+	return candidate
+}
+
+func slotLabel(text uiText, slot saveSlot) string {
+	played := slot.Time
+	t, err := time.ParseInLocation(saveTimeLayout, slot.Time, time.Local)
+	if err == nil {
+		played = t.Format(statsTimeLayout)
+	}
+	if slot.Err != nil {
+		return slot.Name + "  " + text.SlotUnreadable + "  " + played
+	}
+	return slot.Name + "  " + strconv.Itoa(slot.Money) + text.SlotChips + "  " + slot.Rules + "  " + played
+}
+
+func slotLabels(text uiText, slots []saveSlot) []string {
+	labels := make([]string, len(slots))
+	for i, slot := range slots {
+		labels[i] = slotLabel(text, slot)
+	}
+	return labels
+}
+
+// selectedSlot finds the slot under the cursor, -1 on the "---" and "...." entries
+func selectedSlot(m blackjackModel) int {
+	options := currentOptions(m)
+	if m.UiState.Cursor >= len(options) {
+		return -1
+	}
+	selected := options[m.UiState.Cursor]
+	return slices.IndexFunc(m.UiState.Slots, func(s saveSlot) bool { return slotLabel(m.UiText, s) == selected })
+}
+
+func slotPrompt(m blackjackModel) string {
+	switch m.UiState.SlotEdit {
+	case slotEditRename:
+		return m.UiText.LoadPrompt + newlineString + m.UiText.SlotRenamePrompt + m.UiState.SlotInput + "_"
+	case slotEditDelete:
+		return m.UiText.LoadPrompt + newlineString + m.UiText.SlotDeletePrompt + m.UiState.SlotInput
+	case slotEditCode:
+		return m.UiText.LoadPrompt + newlineString + m.UiText.SlotCodePrompt + m.UiState.SlotInput + "_"
+	}
+	prompt := m.UiText.LoadPrompt + newlineString + m.UiText.SlotCodeHelp
+	if len(m.UiState.Slots) > 0 {
+		prompt += "  " + m.UiText.SlotHelp
+	}
+	if m.UiState.Notice != emptyString {
+		prompt += newlineString + m.UiState.Notice
+	}
+	return prompt
+}
+
+// ------------------- Save Slot Keys -------------------------
+
+// handleSlotKey takes the keys of the load screen that manage slots, handled is false
+// for the keys that move the cursor or pick a save
+func handleSlotKey(m blackjackModel, msg tea.KeyMsg) (model tea.Model, cmd tea.Cmd, handled bool) {
+	switch m.UiState.SlotEdit {
+
+	case slotEditRename, slotEditCode:
+		return handleSlotInputKey(m, msg)
+
+	case slotEditDelete:
+		m.UiState.SlotEdit = slotEditNone
+		if msg.Type == tea.KeyRunes && strings.ToLower(msg.String()) == "d" {
+			m.UiState.Slots = slices.DeleteFunc(slices.Clone(m.UiState.Slots), func(s saveSlot) bool { return s.Name == m.UiState.SlotInput })
+			m = writeSlotEdit(m, m.UiText.SlotDeleted+m.UiState.SlotInput)
+			m.UiState.Cursor, m.UiState.LoadPage = 0, 0
+		}
+		return m, nil, true
+	}
+
+	if msg.Type != tea.KeyRunes {
+		return m, nil, false
+	}
+	if strings.ToLower(msg.String()) == "v" {
+		m.UiState.SlotEdit = slotEditCode
+		m.UiState.SlotInput = emptyString
+		return m, nil, true
+	}
+	i := selectedSlot(m)
+	if i < 0 {
+		return m, nil, true
+	}
+	slot := m.UiState.Slots[i]
+	switch strings.ToLower(msg.String()) {
+
+	case "r":
+		m.UiState.SlotEdit = slotEditRename
+		m.UiState.SlotInput = slot.Name
+		m.UiState.SlotTarget = slot.Name
+
+	case "d":
+		m.UiState.SlotEdit = slotEditDelete
+		m.UiState.SlotInput = slot.Name
+
+	case "y":
+		m.UiState.Notice = m.UiText.CodeCopied + slot.Code
+		return m, copySaveCode(slot.Code), true
+
+	case "c":
+		duplicate := slot
+		duplicate.Name = uniqueSlotName(m.UiState.Slots, slot.Name+m.UiText.SlotCopySuffix)
+		m.UiState.Slots = slices.Insert(slices.Clone(m.UiState.Slots), i+1, duplicate)
+		m = writeSlotEdit(m, m.UiText.SlotDuplicated+duplicate.Name)
+	}
+	return m, nil, true
+}
+
+// handleSlotInputKey edits the name of a slot or a save code, a pasted code arrives as one
+// KeyRunes message and tabs of a pasted save.txt line become spaces
+func handleSlotInputKey(m blackjackModel, msg tea.KeyMsg) (tea.Model, tea.Cmd, bool) {
+	limit := slotNameLimit
+	if m.UiState.SlotEdit == slotEditCode {
+		limit = saveCodeLimit
+	}
+	switch msg.Type {
+
+	case tea.KeyEsc:
+		m.UiState.SlotEdit = slotEditNone
+
+	case tea.KeyBackspace:
+		_, size := utf8.DecodeLastRuneInString(m.UiState.SlotInput)
+		m.UiState.SlotInput = m.UiState.SlotInput[:len(m.UiState.SlotInput)-size]
+
+	case tea.KeySpace, tea.KeyTab:
+		if utf8.RuneCountInString(m.UiState.SlotInput) < limit {
+			m.UiState.SlotInput += singleSpaceString
+		}
+
+	case tea.KeyRunes:
+		for _, r := range msg.Runes {
+			if r == '\t' {
+				r = singleSpaceRune
+			}
+			if unicode.IsPrint(r) && utf8.RuneCountInString(m.UiState.SlotInput) < limit {
+				m.UiState.SlotInput += string(r)
+			}
+		}
+
+	case tea.KeyEnter:
+		mode := m.UiState.SlotEdit
+		m.UiState.SlotEdit = slotEditNone
+		if mode == slotEditCode {
+			model, cmd := importSaveCode(m, m.UiState.SlotInput)
+			return model, cmd, true
+		}
+		return renameSlot(m), nil, true
+	}
+	return m, nil, true
+}
+
+func renameSlot(m blackjackModel) blackjackModel {
+	name := strings.TrimSpace(m.UiState.SlotInput)
+	switch {
+	case name == m.UiState.SlotTarget:
+		return m
+	case name == emptyString || name == autosaveSlot:
+		m.UiState.Notice = m.UiText.SlotNameInvalid
+		return m
+	case slices.ContainsFunc(m.UiState.Slots, func(s saveSlot) bool { return s.Name == name }):
+		m.UiState.Notice = m.UiText.SlotNameTaken + name
+		return m
+	}
+	m.UiState.Slots = slices.Clone(m.UiState.Slots)
+	for i := range m.UiState.Slots {
+		if m.UiState.Slots[i].Name == m.UiState.SlotTarget {
+			m.UiState.Slots[i].Name = name
+		}
+	}
+	return writeSlotEdit(m, m.UiText.SlotRenamed+name)
+}
+
+// writeSlotEdit stores the edited list, on an error the list is read back from the file
+func writeSlotEdit(m blackjackModel, notice string) blackjackModel {
+	m.UiState.Notice = notice
+	err := writeSlots(m.UiState.Slots)
+	if err != nil {
+		log.Printf("Error writing save slots: %v\n", err)
+		m.UiState.Notice = m.UiText.SlotWriteFailed + err.Error()
+		m.UiState.Slots = loadSlots()
+	}
+	return m
+}
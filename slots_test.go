@@ -0,0 +1,223 @@
+package main
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// midHandGame deals rounds from a fixed shoe until one is still open after the deal
+func midHandGame(t *testing.T) gameState {
+	t.Helper()
+	for seed := uint32(1); seed < 100; seed++ {
+		gs := gameState{NumberDecks: 1, Payout: 15, PlayerMoney: startingMoney, ReshuffleThreshold: reshuffleThreshold(1, 50)}
+		gs.DrawStack, gs.RandomSeed = newDeck(gs.NumberDecks, make([]card, 0, 52), seed)
+		gs = dealRound(placeBet(gs, 10))
+		if gs.Phase == phasePlay {
+			return gs
+		}
+	}
+	t.Fatal("no seed leaves a hand open")
+	return gameState{}
+}
+
+func findSlot(t *testing.T, slots []saveSlot, name string) saveSlot {
+	t.Helper()
+	for _, slot := range slots {
+		if slot.Name == name {
+			return slot
+		}
+	}
+	t.Fatalf("slot %q not in %v", name, slots)
+	return saveSlot{}
+}
+
+// loadSlot picks the slot on the load screen and confirms it like the player does
+func loadSlot(t *testing.T, name string) blackjackModel {
+	t.Helper()
+	text, err := loadUiStrings("en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := blackjackModel{Game: gameState{Phase: phaseConfig, ConfigStep: configStepLoad}, UiText: finalizeUiStrings(text)}
+	m.UiState.Slots = loadSlots()
+	model, _ := handleConfigStepLoad(m, slotLabel(m.UiText, findSlot(t, m.UiState.Slots, name)))
+	if model.(blackjackModel).Game.ConfigStep != configStepLoadConfirm {
+		t.Fatalf("slot %q did not load: %s", name, model.(blackjackModel).UiState.LoadError)
+	}
+	model, _ = handleConfigSelection(model.(blackjackModel), m.UiText.LoadOldGame)
+	return model.(blackjackModel)
+}
+
+func TestFinishedHandReplacesMidHandSlot(t *testing.T) {
+	resetStorage(t)
+	err := storeSlot("Player", midHandGame(t), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := loadSlot(t, "Player")
+	if m.Game.Phase != phasePlay {
+		t.Fatal("the slot did not load as a hand in progress")
+	}
+	model, _ := withSessionRecord(handleSelection(m, m.UiText.OptionStand))
+	if model.(blackjackModel).Game.Phase != phaseEnd {
+		t.Fatal("standing did not finish the hand")
+	}
+
+	for _, name := range []string{"Player", autosaveSlot} {
+		gs, _, err := decodeGameState(findSlot(t, loadSlots(), name).saveString())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gs.Phase == phasePlay || len(gs.PlayerCards) > 0 {
+			t.Errorf("slot %q still holds the hand in progress", name)
+		}
+		if gs.PlayerMoney != model.(blackjackModel).Game.PlayerMoney {
+			t.Errorf("slot %q has %d chips, the settled round %d", name, gs.PlayerMoney, model.(blackjackModel).Game.PlayerMoney)
+		}
+	}
+}
+
+func TestFinishedRoundCreatesNoSlotForUnsavedGame(t *testing.T) {
+	resetStorage(t)
+	err := storeRound("Player", gameState{NumberDecks: 1, Payout: 15, PlayerMoney: startingMoney, ReshuffleThreshold: 26}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	slots := loadSlots()
+	if len(slots) != 1 || slots[0].Name != autosaveSlot {
+		t.Fatalf("got slots %v, want only %s", slots, autosaveSlot)
+	}
+}
+
+func TestRoundsDoNotRotateBackups(t *testing.T) {
+	resetStorage(t)
+	gs := gameState{NumberDecks: 1, Payout: 15, PlayerMoney: startingMoney, ReshuffleThreshold: 26}
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.Local)
+	for i := range 2 {
+		err := storeSlot("Player", gs, now.Add(time.Duration(i)*time.Minute))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	backup, err := os.ReadFile(saveBackupName(store.path(saveFile), 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range saveBackups + 2 {
+		gs.PlayerMoney += 10
+		err = storeRound("Player", gs, now.Add(time.Duration(i+2)*time.Minute))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	after, err := os.ReadFile(saveBackupName(store.path(saveFile), 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(after) != string(backup) {
+		t.Errorf("rounds rotated the backups:\n%s\nwant\n%s", after, backup)
+	}
+	_, err = os.Stat(saveBackupName(store.path(saveFile), 2))
+	if !os.IsNotExist(err) {
+		t.Errorf("rounds rotated the backups, %s exists", saveBackupName(store.path(saveFile), 2))
+	}
+	if findSlot(t, loadSlots(), "Player").Money != int(gs.PlayerMoney) {
+		t.Error("the slot did not move forward with the rounds")
+	}
+}
+
+// loadScreen lists the stored slots on the load screen with the cursor on the first one
+func loadScreen(t *testing.T) blackjackModel {
+	t.Helper()
+	m := tableModel(t, gameState{Phase: phaseConfig, ConfigStep: configStepLoad})
+	m.UiState.Slots = loadSlots()
+	return m
+}
+
+// pressKeys sends the keys to the load screen, runes one message per key
+func pressKeys(t *testing.T, m blackjackModel, keys ...tea.KeyMsg) blackjackModel {
+	t.Helper()
+	for _, key := range keys {
+		model, _, handled := handleSlotKey(m, key)
+		if !handled {
+			t.Fatalf("%q is not a key of the slots", key.String())
+		}
+		m = model.(blackjackModel)
+	}
+	return m
+}
+
+func runes(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func slotNames(slots []saveSlot) []string {
+	names := make([]string, len(slots))
+	for i, slot := range slots {
+		names[i] = slot.Name
+	}
+	return names
+}
+
+func TestSlotKeys(t *testing.T) {
+	resetStorage(t)
+	gs := gameState{NumberDecks: 1, Payout: 15, PlayerMoney: startingMoney, ReshuffleThreshold: 26}
+	now := time.Now()
+	for _, name := range []string{"Alice", "Bob"} {
+		err := storeSlot(name, gs, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	backspace := tea.KeyMsg{Type: tea.KeyBackspace}
+	enter := tea.KeyMsg{Type: tea.KeyEnter}
+
+	m := pressKeys(t, loadScreen(t), runes("r"), backspace, backspace, backspace, runes("Carol Ann"), enter)
+	if got := strings.Join(slotNames(readSlots()), ","); got != "Carol Ann,Alice" {
+		t.Errorf("after the rename the slots are %s", got)
+	}
+	m = pressKeys(t, m, runes("c"))
+	if got := strings.Join(slotNames(readSlots()), ","); got != "Carol Ann,Carol Ann copy,Alice" {
+		t.Errorf("after the duplicate the slots are %s", got)
+	}
+	m = pressKeys(t, m, runes("d"), runes("x"))
+	if len(readSlots()) != 3 {
+		t.Error("a delete that was not confirmed removed the slot")
+	}
+	m = pressKeys(t, m, runes("d"), runes("D"))
+	if got := strings.Join(slotNames(readSlots()), ","); got != "Carol Ann copy,Alice" {
+		t.Errorf("after the delete the slots are %s", got)
+	}
+	if m.UiState.Notice != m.UiText.SlotDeleted+"Carol Ann" {
+		t.Errorf("notice %q", m.UiState.Notice)
+	}
+
+	for _, name := range []string{"Alice", autosaveSlot, " "} {
+		m = pressKeys(t, loadScreen(t), runes("r"))
+		m.UiState.SlotInput = name
+		m = pressKeys(t, m, enter)
+		if got := strings.Join(slotNames(readSlots()), ","); got != "Carol Ann copy,Alice" {
+			t.Errorf("renamed to %q: the slots are %s", name, got)
+		}
+		if m.UiState.Notice == emptyString {
+			t.Errorf("renamed to %q without a notice", name)
+		}
+	}
+}
+
+func TestSlotLineKeepsName(t *testing.T) {
+	slot := saveSlot{Name: "Sunday  evening", Time: "2026-01-01-12:00:00", Code: "ABC=1234"}
+	got := parseSlotLine(slot.line())
+	if got.Name != slot.Name || got.Time != slot.Time || got.Code != slot.Code {
+		t.Errorf("got %+v, want %+v", got, slot)
+	}
+	slots := []saveSlot{{Name: "Player"}, {Name: "Player 2"}}
+	if name := uniqueSlotName(slots, "Player"); name != "Player 3" {
+		t.Errorf("a third Player is named %q", name)
+	}
+}
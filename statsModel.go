@@ -0,0 +1,302 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	tea "github.com/charmbracelet/bubbletea"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ------------------- Lifetime Statistics Store --------------
+
+// storeSession adds the session to the lifetime statistics when the game ends,
+// a session without a finished hand leaves the file alone
+func storeSession(m blackjackModel) {
+	if m.Session.Hands == 0 {
+		return
+	}
+	err := recordLifetimeStats(store.path(statsFile), m.Game, m.Session.handTotals, time.Now())
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+func recordLifetimeStats(filename string, gs gameState, session handTotals, now time.Time) error {
+	stats, err := loadLifetimeStats(filename)
+	if err != nil {
+		return err // never overwrite a file of a newer version or one that can't be read
+	}
+	rules := rulesSummary(gs.Payout, gs.HitOnSoft17, gs.NumberDecks, penetrationOf(gs))
+
+	i := slices.IndexFunc(stats.RuleSets, func(r ruleSetTotals) bool { return r.Rules == rules })
+	if i < 0 {
+		stats.RuleSets = append(stats.RuleSets, ruleSetTotals{Rules: rules})
+		i = len(stats.RuleSets) - 1
+	}
+	stats.RuleSets[i].Sessions++
+	stats.RuleSets[i].Totals = stats.RuleSets[i].Totals.add(session)
+
+	entry := sessionEntry{Time: now, Rules: rules, Hands: session.Hands, Net: session.Net, Money: int(gs.PlayerMoney)}
+	stats.Bankroll = append(stats.Bankroll, entry)
+	if len(stats.Bankroll) > statsHistoryLimit {
+		stats.Bankroll = stats.Bankroll[len(stats.Bankroll)-statsHistoryLimit:]
+	}
+	stats.BestSessions = append(stats.BestSessions, entry)
+	slices.SortStableFunc(stats.BestSessions, func(a, b sessionEntry) int { return b.Net - a.Net })
+	if len(stats.BestSessions) > statsBestSessions {
+		stats.BestSessions = stats.BestSessions[:statsBestSessions]
+	}
+
+	data, err := json.MarshalIndent(stats, emptyString, "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding %s: %w", filename, err)
+	}
+	return writeFileAtomic(filename, data)
+}
+
+// loadLifetimeStats returns empty statistics of the current version when there is no file yet
+func loadLifetimeStats(filename string) (lifetimeStats, error) {
+	stats := lifetimeStats{Version: statsSchemaVersion}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return stats, nil
+		}
+		return stats, fmt.Errorf("error reading %s: %w", filename, err)
+	}
+	err = json.Unmarshal(data, &stats)
+	if err != nil {
+		return stats, fmt.Errorf("error parsing %s: %w", filename, err)
+	}
+	if stats.Version != statsSchemaVersion {
+		return stats, fmt.Errorf("%s has schema version %d, this version reads %d", filename, stats.Version, statsSchemaVersion)
+	}
+	return stats, nil
+}
+
+// writeFileAtomic writes a temporary file in the same directory, syncs it and renames it over
+// filename, so a crash leaves either the old or the new content but never a torn file
+func writeFileAtomic(filename string, data []byte) error {
+	file, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("error creating a temporary file for %s: %w", filename, err)
+	}
+	tempName := file.Name()
+	_, err = file.Write(data)
+	if err == nil {
+		err = file.Sync()
+	}
+	closeErr := file.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tempName, filename)
+	}
+	if err != nil {
+		removeErr := os.Remove(tempName)
+		if removeErr != nil && !os.IsNotExist(removeErr) {
+			log.Println("Error removing " + tempName + ": " + removeErr.Error())
+		}
+		return fmt.Errorf("error writing %s: %w", filename, err)
+	}
+	return nil
+}
+
+// penetrationOf finds the penetration the ReshuffleThreshold of the game was made of
+func penetrationOf(gs gameState) uint8 {
+	for penetration := uint8(0); penetration <= 100; penetration++ {
+		if reshuffleThreshold(gs.NumberDecks, penetration) >= gs.ReshuffleThreshold {
+			return penetration
+		}
+	}
+	return 100
+}
+
+// ------------------- Statistics Model -----------------------
+
+func newStatsModel(back blackjackModel) statsModel {
+	stats, err := loadLifetimeStats(store.path(statsFile))
+	if err != nil {
+		log.Println(err)
+	}
+	return statsModel{
+		Back:         back,
+		UiText:       back.UiText,
+		Stats:        stats,
+		Failed:       err != nil,
+		WindowWidth:  back.UiState.WindowWidth,
+		WindowHeight: back.UiState.WindowHeight,
+	}
+}
+
+func (m statsModel) Init() tea.Cmd {
+	return tea.WindowSize()
+}
+
+func (m statsModel) View() string {
+
+	poolIndex := getPoolIndex(m.WindowWidth, m.WindowHeight)
+	b := bufferPools[poolIndex].Get().([]byte)[:0]
+	defer bufferPools[poolIndex].Put(b)
+
+	pages := m.pageTitles()
+	b = m.wrapAndPad(b, m.UiText.LifetimeTitle+" - "+pages[m.Page]+" ("+strconv.Itoa(m.Page+1)+"/"+strconv.Itoa(len(pages))+")")
+	b = append(b, newlineRune)
+	b = append(b, newlineRune)
+
+	lines := m.pageLines()
+	end := min(m.Offset+m.visibleLines(), len(lines))
+	for _, line := range lines[m.Offset:end] {
+		b = m.wrapAndPad(b, line)
+		b = append(b, newlineRune)
+	}
+	b = append(b, newlineRune)
+	b = m.wrapAndPad(b, m.UiText.LifetimeHelp)
+	b = append(b, newlineRune)
+
+	b = m.verticalPad(b)
+	return string(b)
+}
+
+func (m statsModel) wrapAndPad(dst []byte, content string) []byte {
+	return wrapAndPadWMToBuffer(dst, content, m.WindowWidth, 2)
+}
+
+func (m statsModel) verticalPad(dst []byte) []byte {
+	return verticalPaddingToBuffer(dst, m.WindowWidth, m.WindowHeight)
+}
+
+func (m statsModel) pageTitles() []string {
+	return []string{m.UiText.LifetimeRuleSets, m.UiText.LifetimeBankroll, m.UiText.LifetimeBest}
+}
+
+// visibleLines leaves room for the title and the help line
+func (m statsModel) visibleLines() int {
+	return max(m.WindowHeight-6, 3)
+}
+
+func (m statsModel) pageLines() []string {
+	switch {
+	case m.Failed:
+		return []string{m.UiText.LifetimeUnreadable + store.path(statsFile)}
+	case len(m.Stats.Bankroll) == 0 && len(m.Stats.RuleSets) == 0:
+		return []string{m.UiText.LifetimeEmpty}
+	}
+
+	var lines []string
+	switch m.Page {
+
+	case 0:
+		ruleSets := slices.Clone(m.Stats.RuleSets)
+		slices.SortStableFunc(ruleSets, func(a, b ruleSetTotals) int { return b.Totals.Hands - a.Totals.Hands })
+		for _, ruleSet := range ruleSets {
+			lines = append(lines, ruleSet.Rules, "  "+m.UiText.LifetimeSessions+strconv.Itoa(ruleSet.Sessions))
+			lines = append(lines, totalsLines(m.UiText, ruleSet.Totals)...)
+		}
+
+	case 1:
+		lines = append(lines, bankrollSparkline(m.Stats.Bankroll))
+		for i := len(m.Stats.Bankroll) - 1; i >= 0; i-- {
+			lines = append(lines, sessionEntryLine(m.Stats.Bankroll[i]))
+		}
+
+	case 2:
+		for i, entry := range m.Stats.BestSessions {
+			lines = append(lines, strconv.Itoa(i+1)+". "+sessionEntryLine(entry))
+		}
+	}
+	return lines
+}
+
+// sessionEntryLine shows the money a session started and ended with, the start is the end minus the net
+func sessionEntryLine(entry sessionEntry) string {
+	return entry.Time.Local().Format(statsTimeLayout) + "  " + strconv.Itoa(entry.Money-entry.Net) + " -> " +
+		strconv.Itoa(entry.Money) + " (" + signedChips(entry.Net) + ", " + strconv.Itoa(entry.Hands) + " hands)  " + entry.Rules
+}
+
+// bankrollSparkline draws the money at the end of each session, scaled between the lowest and the highest
+func bankrollSparkline(entries []sessionEntry) string {
+	if len(entries) == 0 {
+		return emptyString
+	}
+	low, high := entries[0].Money, entries[0].Money
+	for _, entry := range entries {
+		low = min(low, entry.Money)
+		high = max(high, entry.Money)
+	}
+	var sb strings.Builder
+	for _, entry := range entries {
+		level := 0
+		if high > low {
+			level = (entry.Money - low) * (len(sparkBlocks) - 1) / (high - low)
+		}
+		sb.WriteRune(sparkBlocks[level])
+	}
+	return sb.String() + "  " + strconv.Itoa(low) + " - " + strconv.Itoa(high)
+}
+
+func (m statsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+
+	var cmd tea.Cmd
+
+	switch message := msg.(type) {
+
+	case tea.WindowSizeMsg:
+		m.WindowWidth, m.WindowHeight, m.hideCursorPending, cmd = windowSize(m.WindowWidth, m.WindowHeight, m.hideCursorPending, message)
+		return m, cmd
+
+	case tea.KeyMsg:
+		return keyPressStats(m, message)
+
+	case customHideCursorMsg:
+		m.hideCursorPending = false
+		return m, tea.HideCursor
+
+	default:
+		return m, nil
+	}
+}
+
+func keyPressStats(m statsModel, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+
+	case tea.KeyBackspace, tea.KeyEsc:
+		m.Back.UiState.WindowWidth, m.Back.UiState.WindowHeight = m.WindowWidth, m.WindowHeight
+		return m.Back, m.Back.Init()
+
+	case tea.KeyLeft:
+		m.Page = (m.Page + len(m.pageTitles()) - 1) % len(m.pageTitles())
+		m.Offset = 0
+		return m, nil
+
+	case tea.KeyRight, tea.KeyEnter:
+		m.Page = (m.Page + 1) % len(m.pageTitles())
+		m.Offset = 0
+		return m, nil
+
+	case tea.KeyUp:
+		if m.Offset >= 1 {
+			m.Offset--
+		}
+		return m, nil
+
+	case tea.KeyDown:
+		if m.Offset < len(m.pageLines())-m.visibleLines() {
+			m.Offset++
+		}
+		return m, nil
+
+	default:
+		return m, nil
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPenetrationOf(t *testing.T) {
+	for _, decks := range []uint8{1, 2, 6, 8, maxDecks} {
+		for _, penetration := range []uint8{0, 50, 75, 100} {
+			gs := gameState{NumberDecks: decks, ReshuffleThreshold: reshuffleThreshold(decks, penetration)}
+			got := penetrationOf(gs)
+			if got != penetration {
+				t.Errorf("%d decks at %d %%: shown as %d %%", decks, penetration, got)
+			}
+		}
+	}
+}
+
+func TestRecordLifetimeStats(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), statsFile)
+	gs := gameState{NumberDecks: 6, Payout: 15, PlayerMoney: 120, ReshuffleThreshold: reshuffleThreshold(6, 75)}
+	now := time.Date(2024, 5, 1, 20, 0, 0, 0, time.UTC)
+	for _, net := range []int{20, -30, 50} {
+		err := recordLifetimeStats(filename, gs, handTotals{Hands: 10, Net: net}, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stats, err := loadLifetimeStats(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats.RuleSets) != 1 || stats.RuleSets[0].Sessions != 3 || stats.RuleSets[0].Totals.Hands != 30 {
+		t.Fatalf("rule sets %+v; want one of three sessions and 30 hands", stats.RuleSets)
+	}
+	if !strings.Contains(stats.RuleSets[0].Rules, "75 % penetration") {
+		t.Errorf("rule set %q", stats.RuleSets[0].Rules)
+	}
+	if len(stats.Bankroll) != 3 || stats.Bankroll[0].Net != 20 {
+		t.Errorf("bankroll %+v; want three sessions, oldest first", stats.Bankroll)
+	}
+	if stats.BestSessions[0].Net != 50 || stats.BestSessions[2].Net != -30 {
+		t.Errorf("best sessions %+v; want them by net, best first", stats.BestSessions)
+	}
+}
+
+func TestNewerLifetimeStatsAreKept(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), statsFile)
+	newer := []byte(`{"version": 99}`)
+	err := os.WriteFile(filename, newer, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = recordLifetimeStats(filename, gameState{NumberDecks: 1, Payout: 15}, handTotals{Hands: 1}, time.Now())
+	if err == nil {
+		t.Error("a session is stored in a file of a newer version")
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != string(newer) {
+		t.Errorf("the file became %s", data)
+	}
+}
+
+func TestStatsPagesShowSessions(t *testing.T) {
+	text, err := loadUiStrings("en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := sessionEntry{Time: time.Now(), Rules: "3:2, S17, 6 decks, 75 % penetration", Hands: 12, Net: -40, Money: 60}
+	m := statsModel{
+		UiText: finalizeUiStrings(text),
+		Stats: lifetimeStats{
+			RuleSets:     []ruleSetTotals{{Rules: entry.Rules, Totals: handTotals{Hands: 12, Net: -40}, Sessions: 1}},
+			Bankroll:     []sessionEntry{entry},
+			BestSessions: []sessionEntry{entry},
+			Version:      statsSchemaVersion,
+		},
+	}
+	for page, want := range []string{entry.Rules, "100 -> 60", "1. "} {
+		m.Page = page
+		if !strings.Contains(strings.Join(m.pageLines(), "\n"), want) {
+			t.Errorf("page %d does not show %q: %q", page, want, m.pageLines())
+		}
+	}
+
+	m.Stats = lifetimeStats{Version: statsSchemaVersion}
+	if lines := m.pageLines(); len(lines) != 1 || lines[0] != m.UiText.LifetimeEmpty {
+		t.Errorf("without sessions: %q", lines)
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// ------------------- Storage --------------------------------
+
+// openStorage resolves the one directory all files of the game live in, so `go run`,
+// an installed binary and a double-clicked exe read and write the same saves.
+// The -data-dir flag wins over dataDirEnv, which wins over the data directory of the platform.
+func openStorage(flagDir string) (storage, error) {
+	dir := flagDir
+	if dir == emptyString {
+		dir = os.Getenv(dataDirEnv)
+	}
+	if dir == emptyString {
+		var err error
+		dir, err = defaultDataDir()
+		if err != nil {
+			return storage{}, err
+		}
+	}
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return storage{}, fmt.Errorf("error creating data directory %s: %w", dir, err)
+	}
+	return storage{Dir: dir}, nil
+}
+
+// defaultDataDir is $XDG_DATA_HOME/go-blackjack-tui (~/.local/share by default) on Linux and
+// the other Unix systems, Application Support on macOS and %LocalAppData% on Windows
+func defaultDataDir() (string, error) {
+	switch runtime.GOOS {
+	case "windows":
+		dir, err := os.UserCacheDir() // %LocalAppData%
+		if err != nil {
+			return emptyString, fmt.Errorf("error finding the data directory, set %s or -data-dir: %w", dataDirEnv, err)
+		}
+		return filepath.Join(dir, appName), nil
+	case "darwin":
+		dir, err := os.UserConfigDir() // ~/Library/Application Support
+		if err != nil {
+			return emptyString, fmt.Errorf("error finding the data directory, set %s or -data-dir: %w", dataDirEnv, err)
+		}
+		return filepath.Join(dir, appName), nil
+	}
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == emptyString || !filepath.IsAbs(dataHome) { // relative paths are invalid per the XDG spec
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return emptyString, fmt.Errorf("error finding the data directory, set %s or -data-dir: %w", dataDirEnv, err)
+		}
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dataHome, appName), nil
+}
+
+func (s storage) path(name string) string {
+	return filepath.Join(s.Dir, name)
+}
+
+// resource finds a file that ships with the game like strings.json: a copy in the data
+// directory overrides the one next to the executable, `go run` finds it in the working directory
+func (s storage) resource(name string) string {
+	candidates := []string{s.path(name)}
+	exePath, err := os.Executable()
+	if err == nil {
+		candidates = append(candidates, filepath.Join(filepath.Dir(exePath), name))
+	}
+	for _, candidate := range candidates {
+		_, err = os.Stat(candidate)
+		if err == nil {
+			return candidate
+		}
+	}
+	return name
+}
+
+// adoptLegacyFiles copies the files older versions wrote next to the executable or into
+// the working directory, so an update doesn't lose saves. The originals stay where they are.
+// It runs after setupLogging, the log goes into the data directory as well.
+func (s storage) adoptLegacyFiles() {
+	dirs := []string{emptyString} // the working directory
+	exePath, err := os.Executable()
+	if err == nil {
+		dirs = append([]string{filepath.Dir(exePath)}, dirs...)
+	}
+	for _, name := range legacyFiles {
+		target := s.path(name)
+		_, err = os.Stat(target)
+		if err == nil || !os.IsNotExist(err) {
+			continue
+		}
+		for _, dir := range dirs {
+			source := filepath.Join(dir, name)
+			if sameFile(source, target) {
+				continue
+			}
+			err = copyFile(source, target)
+			if err == nil {
+				log.Println("Copied " + source + " to " + target)
+				break
+			}
+			if !os.IsNotExist(err) {
+				log.Println("Error copying " + source + " to " + target + ": " + err.Error())
+			}
+		}
+	}
+}
+
+func sameFile(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
+
+func copyFile(source, target string) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		closeErr := in.Close()
+		if closeErr != nil {
+			log.Println("Error while closing " + source + ": " + closeErr.Error())
+		}
+	}()
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(target, data)
+}
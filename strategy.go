@@ -0,0 +1,205 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+// ------------------- Strategies -----------------------------
+
+func viewOf(gs gameState) handView {
+	total, soft := calculateSoftHand(gs.PlayerCards)
+	return handView{
+		PlayerCards: gs.PlayerCards,
+		Upcard:      gs.DealerCards[0],
+		PlayerTotal: total,
+		Soft:        soft,
+		NumberDecks: gs.NumberDecks,
+		CanDouble:   canDouble(gs),
+		HitOnSoft17: gs.HitOnSoft17,
+	}
+}
+
+// soft means one ace is still counted as 11
+func calculateSoftHand(hand []card) (total int, soft bool) {
+	aces := 0
+	for _, c := range hand {
+		total += c.Value
+		if c.Rank == ace {
+			aces++
+		}
+	}
+	for aces >= 1 && total >= 22 {
+		total -= 10
+		aces--
+	}
+	return total, aces >= 1
+}
+
+func mimicDealerStrategy(v handView) int {
+	if v.PlayerTotal <= 16 || (v.PlayerTotal == 17 && v.Soft && v.HitOnSoft17) {
+		return actionHit
+	}
+	return actionStand
+}
+
+func neverBustStrategy(v handView) int {
+	if v.PlayerTotal <= 11 || (v.Soft && v.PlayerTotal <= 17) {
+		return actionHit
+	}
+	return actionStand
+}
+
+// ------------------- Basic Strategy -------------------------
+
+// basicStrategy plays the best total-dependent option. The table behind it is computed
+// from the rules of the table instead of being typed in, so it follows every config change.
+func basicStrategy(v handView) int {
+	table := strategyTableFor(strategyKey{NumberDecks: v.NumberDecks, HitOnSoft17: v.HitOnSoft17, CanDouble: v.CanDouble})
+	return table.lookup(v).bestAction(v.CanDouble)
+}
+
+func (t *strategyTable) lookup(v handView) actionEVs {
+	upcard := cardIndex(v.Upcard)
+	if v.Soft {
+		return t.Soft[v.PlayerTotal][upcard]
+	}
+	return t.Hard[v.PlayerTotal][upcard]
+}
+
+// strategyTableFor is safe to call from the simulator workers, the first caller builds the table
+func strategyTableFor(key strategyKey) *strategyTable {
+	strategyTablesMu.Lock()
+	defer strategyTablesMu.Unlock()
+	table, ok := strategyTables[key]
+	if !ok {
+		table = buildStrategyTable(key)
+		strategyTables[key] = table
+	}
+	return table
+}
+
+// Every cell is evaluated with a typical hand for its total removed from a full shoe,
+// e.g. hard 16 as 10-6 and soft 17 as A-6. Only 21 needs three cards.
+func buildStrategyTable(key strategyKey) *strategyTable {
+	table := &strategyTable{}
+	for upcard := range 10 {
+		for total := 4; total <= 20; total++ {
+			first, second := representativeHard(total)
+			table.Hard[total][upcard] = evaluateStart(key, upcard, first, second)
+		}
+		for total := 12; total <= 20; total++ {
+			table.Soft[total][upcard] = evaluateStart(key, upcard, 0, total-12)
+		}
+		table.Hard[21][upcard] = evaluateStart(key, upcard, 9, 9, 0)
+		table.Soft[21][upcard] = evaluateStart(key, upcard, 0, 4, 4)
+	}
+	return table
+}
+
+// returns card indices, see cardIndex
+func representativeHard(total int) (int, int) {
+	if total >= 12 {
+		return 9, total - 11
+	}
+	low := total / 2
+	return low - 1, total - low - 1
+}
+
+func evaluateStart(key strategyKey, upcard int, playerCards ...int) actionEVs {
+	shoe := fullShoe(key.NumberDecks)
+	hardTotal := 0
+	hasAce := false
+	for _, c := range playerCards {
+		shoe[c]--
+		hardTotal += c + 1
+		hasAce = hasAce || c == 0
+	}
+	shoe[upcard]--
+	engine := newEVEngine(shoe, upcard, key.HitOnSoft17, key.CanDouble, false)
+	return engine.evaluate(shoe, hardTotal, hasAce)
+}
+
+func legalAction(gs gameState, action int) bool {
+	switch action {
+	case actionHit, actionStand, actionSurrender:
+		return gs.Phase == phasePlay && gs.Turn == turnPlayer
+	case actionDouble:
+		return gs.Phase == phasePlay && gs.Turn == turnPlayer && canDouble(gs)
+	default:
+		return false
+	}
+}
+
+func applyAction(gs gameState, action int) gameState {
+	switch action {
+	case actionHit:
+		return playerHit(gs, normalLoose)
+	case actionStand:
+		return dealerPlay(gs, normalWin, normalLoose, normalDraw)
+	case actionDouble:
+		return playerDouble(gs)
+	case actionSurrender:
+		return playerSurrender(gs)
+	default:
+		return gs
+	}
+}
+
+// ------------------- Strategy Command -----------------------
+
+func runStrategy(args []string) int {
+	flags := flag.NewFlagSet(commandStrategy, flag.ContinueOnError)
+	decks := flags.Int("decks", 6, "number of decks (1-"+strconv.Itoa(maxDecks)+")")
+	h17 := flags.Bool("h17", false, "dealer hits on soft 17")
+	noDouble := flags.Bool("no-double", false, "chart for a player who can't afford to double")
+	composition := flags.Bool("composition", false, "also list the two-card hands that composition-dependent strategy plays differently")
+	indices := flags.Bool("indices", false, "also list the Hi-Lo index plays (Illustrious 18 and Fab 4)")
+	err := flags.Parse(args)
+	if err != nil {
+		return 2
+	}
+	if *decks < 1 || *decks > maxDecks {
+		fmt.Println("strategy: decks must be between 1 and " + strconv.Itoa(maxDecks))
+		return 2
+	}
+
+	key := strategyKey{NumberDecks: uint8(*decks), HitOnSoft17: *h17, CanDouble: !*noDouble}
+	table := strategyTableFor(key)
+	printStrategyChart(os.Stdout, key, table)
+	if *composition {
+		fmt.Println()
+		printCompositionExceptions(os.Stdout, key, table)
+	}
+	if *indices {
+		fmt.Println()
+		printIndexPlays(os.Stdout)
+	}
+	return 0
+}
+
+func printStrategyChart(w io.Writer, key strategyKey, table *strategyTable) {
+	dealerRule := "S17"
+	if key.HitOnSoft17 {
+		dealerRule = "H17"
+	}
+	_, _ = fmt.Fprintf(w, "%d decks, %s - H hit, S stand, D double, R surrender\n\n", key.NumberDecks, dealerRule)
+	_, _ = fmt.Fprintln(w, "        2  3  4  5  6  7  8  9 10  A")
+	for total := 4; total <= 20; total++ {
+		printChartRow(w, "hard "+strconv.Itoa(total), table.Hard[total], key.CanDouble)
+	}
+	for total := 12; total <= 20; total++ {
+		printChartRow(w, "soft "+strconv.Itoa(total), table.Soft[total], key.CanDouble)
+	}
+}
+
+func printChartRow(w io.Writer, label string, row [10]actionEVs, doubleAllowed bool) {
+	_, _ = fmt.Fprintf(w, "%-7s", label)
+	for _, upcard := range [10]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 0} {
+		_, _ = fmt.Fprintf(w, "  %c", actionLetters[row[upcard].bestAction(doubleAllowed)])
+	}
+	_, _ = fmt.Fprintln(w)
+}
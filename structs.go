@@ -1,5 +1,12 @@
 package main
 
+import (
+	"bufio"
+	"encoding/json"
+	"math/rand/v2"
+	"time"
+)
+
 // https://github.com/dkorunic/betteralign
 
 // ------------------- miscModels -----------------------------
@@ -23,6 +30,7 @@ type errorModel struct {
 
 type gameOverModel struct {
 	message           string
+	Stats             []string // sessionLines of the finished game
 	WindowWidth       int
 	WindowHeight      int
 	hideCursorPending bool
@@ -31,6 +39,12 @@ type gameOverModel struct {
 type customHideCursorMsg struct{}
 type customErrorMsg struct{ err error }
 type customFinishMsg struct{ languages []string }
+type customAnalysisMsg struct {
+	evs  actionEVs
+	hand handID
+}
+type customDrillTickMsg struct{ generation int }
+type customAutoTickMsg struct{ generation int }
 
 // ------------------- bjModel --------------------------------
 
@@ -76,14 +90,94 @@ type uiText struct {
 	StartUpPrompt          string   `json:"start-up-prompt"`
 	LoadPrompt             string   `json:"load-prompt"`
 	LoadFailStatus         string   `json:"load-fail-status"`
+	LoadFailTampered       string   `json:"load-fail-tampered"`
+	LoadFailUnsigned       string   `json:"load-fail-unsigned"`
+	LoadFailUnverifiable   string   `json:"load-fail-unverifiable"`
+	LoadFailChecksum       string   `json:"load-fail-checksum"`
+	LoadFailInvalid        string   `json:"load-fail-invalid"`
+	SlotHelp               string   `json:"slot-help"`
+	SlotRenamePrompt       string   `json:"slot-rename-prompt"`
+	SlotDeletePrompt       string   `json:"slot-delete-prompt"`
+	SlotDeleted            string   `json:"slot-deleted"`
+	SlotRenamed            string   `json:"slot-renamed"`
+	SlotDuplicated         string   `json:"slot-duplicated"`
+	SlotCopySuffix         string   `json:"slot-copy-suffix"`
+	SlotNameInvalid        string   `json:"slot-name-invalid"`
+	SlotNameTaken          string   `json:"slot-name-taken"`
+	SlotWriteFailed        string   `json:"slot-write-failed"`
+	SlotUnreadable         string   `json:"slot-unreadable"`
+	SlotChips              string   `json:"slot-chips"`
+	SlotDefaultName        string   `json:"slot-default-name"`
+	SlotCodeHelp           string   `json:"slot-code-help"`
+	SlotCodePrompt         string   `json:"slot-code-prompt"`
+	CodeCopied             string   `json:"code-copied"`
+	CodeMidHand            string   `json:"code-mid-hand"`
+	CodeMissing            string   `json:"code-missing"`
+	CodeImported           string   `json:"code-imported"`
+	CodeImportedName       string   `json:"code-imported-name"`
+	QRHelp                 string   `json:"qr-help"`
+	LoadRepaired           string   `json:"load-repaired"`
+	QRTooSmall             string   `json:"qr-too-small"`
 	Surrendered            string   `json:"option_surrender_msg"`
+	KeysHelp               string   `json:"keys-help"`
+	HintLabel              string   `json:"hint-label"`
+	MistakeLabel           string   `json:"mistake-label"`
+	MistakeSummary         string   `json:"mistake-summary"`
+	InsteadOf              string   `json:"instead-of"`
+	Versus                 string   `json:"versus"`
+	HardHand               string   `json:"hard-hand"`
+	SoftHand               string   `json:"soft-hand"`
+	AnalysisTitle          string   `json:"analysis-title"`
+	AnalysisPending        string   `json:"analysis-pending"`
+	TrainerLabel           string   `json:"trainer-label"`
+	CountQuestionRunning   string   `json:"count-question-running"`
+	CountQuestionTrue      string   `json:"count-question-true"`
+	CountInputPrompt       string   `json:"count-input-prompt"`
+	CountCorrect           string   `json:"count-correct"`
+	CountWrong             string   `json:"count-wrong"`
+	CountRunning           string   `json:"count-running"`
+	CountTrue              string   `json:"count-true"`
+	CountMissed            string   `json:"count-missed"`
+	CountContinue          string   `json:"count-continue"`
+	CountingDrill          string   `json:"counting-drill"`
+	DrillTitle             string   `json:"drill-title"`
+	DrillStart             string   `json:"drill-start"`
+	DrillPace              string   `json:"drill-pace"`
+	DrillCards             string   `json:"drill-cards"`
+	DrillSystem            string   `json:"drill-system"`
+	DrillQuestion          string   `json:"drill-question"`
+	DrillGrade             string   `json:"drill-grade"`
+	DrillAnswer            string   `json:"drill-answer"`
+	DrillHistory           string   `json:"drill-history"`
+	IndexPlayLabel         string   `json:"index-play-label"`
+	CompositionLabel       string   `json:"composition-label"`
+	AutoLabel              string   `json:"auto-label"`
+	AutoRounds             string   `json:"auto-rounds"`
+	AutoPaused             string   `json:"auto-paused"`
+	StatsTitle             string   `json:"stats-title"`
+	StatsHands             string   `json:"stats-hands"`
+	StatsResults           string   `json:"stats-results"`
+	StatsSpecial           string   `json:"stats-special"`
+	StatsNet               string   `json:"stats-net"`
+	StatsBiggestWin        string   `json:"stats-biggest-win"`
+	StatsStreaks           string   `json:"stats-streaks"`
+	StatsAverageBet        string   `json:"stats-average-bet"`
+	LifetimeStats          string   `json:"lifetime-stats"`
+	LifetimeTitle          string   `json:"lifetime-title"`
+	LifetimeRuleSets       string   `json:"lifetime-rule-sets"`
+	LifetimeBankroll       string   `json:"lifetime-bankroll"`
+	LifetimeBest           string   `json:"lifetime-best"`
+	LifetimeSessions       string   `json:"lifetime-sessions"`
+	LifetimeEmpty          string   `json:"lifetime-empty"`
+	LifetimeUnreadable     string   `json:"lifetime-unreadable"`
+	LifetimeHelp           string   `json:"lifetime-help"`
 	PhaseConfigStepDecks   []string `json:"-"`
 }
 
 type card struct {
-	Rank  string
-	Suit  string
-	Value int
+	Rank  string `json:"rank"`
+	Suit  string `json:"suit"`
+	Value int    `json:"value"` // aces are 11, calculateHand counts them as 1 when needed
 }
 
 type blackjackCards struct {
@@ -102,6 +196,8 @@ type gameState struct {
 	Turn               int
 	Phase              int
 	ConfigStep         int
+	Outcome            int
+	HiLoCount          int // Hi-Lo running count of the CardsDealt cards, the hole card included
 	RandomSeed         uint32
 	Bet                uint16
 	PlayerMoney        uint16
@@ -113,6 +209,7 @@ type gameState struct {
 	HitOnSoft17        bool
 	IsSoft17           bool
 	NeedReshuffle      bool
+	DealerBroke        bool
 }
 
 type savableGameState struct {
@@ -120,22 +217,53 @@ type savableGameState struct {
 	playerMoney        uint16
 	ReshuffleThreshold uint16
 	CardsDealt         uint16
+	Bet                uint16 // the hand in progress, 0 between rounds
 	NumberDecks        uint8
 	Payout             uint8
+	PlayerCardCount    uint8 // the hand is the last cards of CardsDealt, see restoreHand
+	Turn               uint8
 	HitOnSoft17        bool
 	NeedReshuffle      bool
 }
 
 type uiState struct {
+	Mistakes          map[string]mistakeTally // by habit, e.g. "hard 16 vs 10: Hit instead of Surrender"
 	Message           string
-	Saves             []string
+	LoadError         string // why the save picked last was rejected, see loadErrorReason
+	LastMistake       string
+	Slots             []saveSlot
+	SlotInput         string // the new name while renaming, the slot to delete while asking, the typed save code
+	SlotTarget        string // the slot being renamed
+	Notice            string // feedback of the last slot or clipboard action
 	Cursor            int
 	langLoadPage      int
 	LoadPage          int
 	WindowWidth       int
 	WindowHeight      int
-	firstTime         bool
+	MistakeCount      int
+	MistakeCost       float64 // expected chips lost against basic strategy
+	Analysis          actionEVs
+	AnalysisHand      handID
 	hideCursorPending bool
+	SlotEdit          uint8 // slotEditNone, slotEditRename, slotEditDelete or slotEditCode
+	ShowHint          bool
+	MistakeFeedback   bool
+	ShowAnalysis      bool
+	ShowStats         bool
+	ShowQR            bool
+	Saved             bool // the hand on the table is in save.txt already
+	AnalysisReady     bool
+}
+
+// handID tells whether a background result still belongs to the hand on the table
+type handID struct {
+	RandomSeed uint32
+	CardsDealt uint16
+}
+
+type mistakeTally struct {
+	Count int
+	Cost  float64
 }
 
 type blackjackModel struct {
@@ -146,5 +274,406 @@ type blackjackModel struct {
 	// Background goroutines don't modify the model directly
 	UiText  uiText
 	UiState uiState
+	Trainer trainerState
+	Auto    autoState
+	Session sessionStats
+	Slot    string // the save slot the game is saved into
 	Game    gameState
 }
+
+// saveSlot is one line of save.txt, Money and Rules are decoded from Code unless Err is set
+type saveSlot struct {
+	Err   error
+	Name  string
+	Time  string
+	Code  string
+	Rules string
+	Money int
+}
+
+// sessionStats tallies the rounds since the game was started or loaded, see record
+type sessionStats struct {
+	handTotals
+	Streak      int    // positive while winning, negative while losing
+	MoneyBefore uint16 // PlayerMoney before the bet of the current round
+	Recorded    bool
+}
+
+// handTotals is shared by a session and the lifetime totals of a rule set
+type handTotals struct {
+	Hands             int `json:"hands"`
+	Wins              int `json:"wins"`
+	Losses            int `json:"losses"` // surrenders included
+	Pushes            int `json:"pushes"`
+	Naturals          int `json:"naturals"`
+	Busts             int `json:"busts"`
+	Doubles           int `json:"doubles"`
+	Surrenders        int `json:"surrenders"`
+	Net               int `json:"net"`
+	BiggestWin        int `json:"biggestWin"`
+	Wagered           int `json:"wagered"`
+	LongestWinStreak  int `json:"longestWinStreak"`
+	LongestLossStreak int `json:"longestLossStreak"`
+}
+
+// autoState lets a strategy and a bet policy play, see handleAutoTick
+type autoState struct {
+	Bettor      bettor
+	Strategy    string // key of strategies
+	Policy      string // key of betPolicies
+	Pace        time.Duration
+	Generation  int // ticks of an older autoplay run are ignored
+	Rounds      int
+	MoneyBefore uint16 // PlayerMoney before the bet of the current round
+	On          bool
+	Paused      bool
+	Recorded    bool // the bettor has counted the finished round
+}
+
+// ------------------- Count Trainer --------------------------
+
+type countSystem struct {
+	Name     string  `json:"name"`
+	Tags     [10]int `json:"tags"` // by cardIndex: A, 2, 3, 4, 5, 6, 7, 8, 9, 10
+	Balanced bool    `json:"balanced"`
+}
+
+type trainerState struct {
+	Systems        []countSystem
+	Missed         []card // cards dealt since the previous count check
+	Input          string
+	Result         string
+	System         int // 0 = trainer off, otherwise index+1 into Systems
+	Rounds         int // rounds since the previous count check
+	Asked          int
+	Correct        int
+	CheckpointSeed uint32
+	Checkpoint     uint16 // CardsDealt at the previous count check
+	AskTrueCount   bool
+	Answered       bool
+	WasCorrect     bool
+}
+
+// ------------------- Counting Drill -------------------------
+
+type drillModel struct {
+	Started           time.Time
+	Systems           []countSystem
+	Cards             []card
+	History           []drillResult
+	Back              blackjackModel // the start-up screen to return to
+	UiText            uiText
+	Input             string
+	Result            string
+	Pace              time.Duration
+	CardCount         int
+	System            int
+	Stage             int
+	Shown             int // cards flashed so far, the last one is on screen
+	Generation        int // ticks of an aborted drill are ignored
+	Cursor            int
+	WindowWidth       int
+	WindowHeight      int
+	hideCursorPending bool
+}
+
+type statsModel struct {
+	Back              blackjackModel // the start-up screen to return to
+	UiText            uiText
+	Stats             lifetimeStats
+	Page              int
+	Offset            int // first line of the page on screen
+	WindowWidth       int
+	WindowHeight      int
+	Failed            bool // the file exists but can't be read, see loadLifetimeStats
+	hideCursorPending bool
+}
+
+// lifetimeStats is the content of stats.json, Version is statsSchemaVersion
+type lifetimeStats struct {
+	RuleSets     []ruleSetTotals `json:"ruleSets"`
+	Bankroll     []sessionEntry  `json:"bankroll"`     // the last statsHistoryLimit sessions, oldest first
+	BestSessions []sessionEntry  `json:"bestSessions"` // by net, best first
+	Version      int             `json:"version"`
+}
+
+type ruleSetTotals struct {
+	Rules    string     `json:"rules"` // see rulesSummary
+	Totals   handTotals `json:"totals"`
+	Sessions int        `json:"sessions"`
+}
+
+type sessionEntry struct {
+	Time  time.Time `json:"time"`
+	Rules string    `json:"rules"`
+	Hands int       `json:"hands"`
+	Net   int       `json:"net"`
+	Money int       `json:"money"` // at the end of the session
+}
+
+type drillResult struct {
+	Time     time.Time
+	System   string
+	Grade    string
+	Cards    int
+	MissedBy int // answer minus the actual running count
+	Pace     time.Duration
+	Elapsed  time.Duration
+}
+
+// ------------------- Bot Protocol ---------------------------
+
+type botConn struct {
+	scanner *bufio.Scanner
+	encoder *json.Encoder
+	failed  error // the first write error, the session ends at the next read
+}
+
+type botRules struct {
+	Payout      string `json:"payout"`
+	Decks       int    `json:"decks"`
+	Penetration int    `json:"pen"`
+	HitOnSoft17 bool   `json:"h17"`
+}
+
+// botHello is the handshake in both directions, the answer fills in Protocol and the Seed in effect
+type botHello struct {
+	Type     string   `json:"type"`
+	Rules    botRules `json:"rules"`
+	Seed     uint64   `json:"seed"`
+	Money    int      `json:"money"`
+	Protocol int      `json:"protocol,omitempty"`
+}
+
+type botRequest struct {
+	Action string `json:"action"`
+	Amount int    `json:"amount"`
+}
+
+type botState struct {
+	Type        string   `json:"type"`
+	Phase       string   `json:"phase"`
+	PlayerCards []card   `json:"playerCards"`
+	DealerCards []card   `json:"dealerCards"` // only the upcard while the round runs
+	Legal       []string `json:"legal"`
+	Bets        []int    `json:"bets,omitempty"`
+	PlayerTotal int      `json:"playerTotal"`
+	Bet         uint16   `json:"bet"`
+	PlayerMoney uint16   `json:"playerMoney"`
+	CardsDealt  uint16   `json:"cardsDealt"`
+	Reshuffled  bool     `json:"reshuffled"` // a new shoe, counts start over
+}
+
+type botResult struct {
+	Type        string `json:"type"`
+	Outcome     string `json:"outcome"`
+	PlayerCards []card `json:"playerCards"`
+	DealerCards []card `json:"dealerCards"`
+	Net         int    `json:"net"`
+	PlayerTotal int    `json:"playerTotal"`
+	DealerTotal int    `json:"dealerTotal"`
+	PlayerMoney uint16 `json:"playerMoney"`
+	DealerBroke bool   `json:"dealerBroke"`
+}
+
+type botOver struct {
+	Type        string `json:"type"`
+	Reason      string `json:"reason"`
+	PlayerMoney uint16 `json:"playerMoney"`
+}
+
+type botError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// ------------------- Simulator ------------------------------
+
+type strategy func(v handView) int
+
+type handView struct {
+	PlayerCards []card
+	Upcard      card
+	TrueCount   float64 // Hi-Lo, only set by countedView and the simulator
+	PlayerTotal int
+	NumberDecks uint8
+	Soft        bool
+	CanDouble   bool
+	HitOnSoft17 bool
+}
+
+type simConfig struct {
+	Strategy      strategy
+	Policy        betPolicy
+	StrategyName  string
+	PolicyName    string
+	Rounds        int64
+	Seed          uint64
+	Workers       int
+	MinBet        int
+	MaxBet        int
+	Bankroll      int // chips to start with and after every ruin
+	Spread        int // units of the ramp at its top
+	KellyFraction float64
+	Bet           uint16 // one betting unit
+	NumberDecks   uint8
+	Payout        uint8
+	Penetration   uint8
+	HitOnSoft17   bool
+}
+
+// ruinStats keeps every final bankroll and the bankrolls at the chart checkpoints,
+// percentiles need all of them
+type ruinStats struct {
+	Finals       []int
+	Trajectories [ruinChartWidth + 1][]int
+	Sessions     int64
+	Ruined       int64
+	Reached      int64
+	Rounds       int64
+}
+
+// simFlags points at the parsed flags of defineSimFlags
+type simFlags struct {
+	Rounds       *int64
+	Payout       *string
+	HitOnSoft17  *bool
+	Decks        *int
+	Pen          *int
+	StrategyName *string
+	Bet          *int
+	MinBet       *int
+	MaxBet       *int
+	Bankroll     *int
+	Spread       *int
+	Kelly        *float64
+	Seed         *uint64
+	Workers      *int
+}
+
+type simStats struct {
+	Histogram [2*histogramOffset + 1]int64 // net result per round in tenths of the bet
+	Outcomes  [9]int64                     // indexed by the outcome constants
+	Rounds    int64
+	NetChips  int64
+	Wagered   int64 // initial bets, doubles are counted in the net result only
+	Ruins     int64
+	Mean      float64 // net result per round in units of the bet
+	M2        float64 // sum of squared deviations from Mean
+	ChipMean  float64 // net result per round in chips
+	ChipM2    float64 // sum of squared deviations from ChipMean
+}
+
+// ------------------- Bet Policies ---------------------------
+
+// betPolicy returns the wanted bet, clampBet applies the table limits and the bankroll
+type betPolicy func(v betView) int
+
+type betView struct {
+	TrueCount     float64
+	Edge          float64 // estimated player edge for the coming round
+	KellyFraction float64
+	Unit          int
+	Spread        int
+	Bankroll      int
+	LastBet       int // 0 before the first round
+	LastNet       int
+	Wins          int // wins in a row, pushes don't break a streak
+}
+
+type bettor struct {
+	Policy        betPolicy
+	System        countSystem
+	OffTheTop     float64 // baselineEdge of the rules
+	KellyFraction float64
+	Running       int
+	Unit          int
+	MinBet        int
+	MaxBet        int
+	Spread        int
+	LastBet       int
+	LastNet       int
+	Wins          int
+	Seed          uint32 // RandomSeed of the counted shoe
+	CardsDealt    uint16
+}
+
+// ------------------- Strategy Engine ------------------------
+
+type shoeCounts [10]int // see cardIndex
+
+type actionEVs [5]float64 // indexed by the action constants
+
+type evEngine struct {
+	dealerCache map[shoeCounts][6]float64
+	playerCache map[shoeCounts]actionEVs
+	dist        [6]float64
+	upcard      int
+	hitOnSoft17 bool
+	canDouble   bool
+	exact       bool
+}
+
+type strategyKey struct {
+	NumberDecks uint8
+	HitOnSoft17 bool
+	CanDouble   bool
+}
+
+type compositionKey struct {
+	Rules  strategyKey
+	Upcard int
+	Cards  [10]uint8 // player cards by cardIndex
+}
+
+// indexPlay switches the play of a hard total against an upcard at a Hi-Lo true count,
+// a Below of 0 leaves the hand to the strategy underneath
+type indexPlay struct {
+	Set       string
+	Total     int
+	Upcard    int // cardIndex
+	Index     float64
+	AtOrAbove int
+	Below     int
+}
+
+type strategyTable struct {
+	Hard [22][10]actionEVs // by hard total and upcard, totals 4-21 are filled
+	Soft [22][10]actionEVs // by soft total and upcard, totals 12-21 are filled
+}
+
+// blackjackEnv holds every buffer of the environment, table i owns the i-th slice
+// of observations and masks
+type blackjackEnv struct {
+	tables        []envTable
+	observations  []float32 // envObservationSize per table
+	masks         []bool    // len(envActions) per table
+	rewards       []float32 // in bets
+	dones         []bool
+	settledOnDeal []float32 // rewards of the naturals dealt since the last call
+}
+
+type envTable struct {
+	gs   gameState
+	shoe []card // the full shoe, DrawStack is its undealt tail
+	seen shoeCounts
+	pcg  *rand.PCG
+	rng  *rand.Rand
+}
+
+// storage points every file of the game into one data directory, see openStorage
+type storage struct {
+	Dir string
+}
+
+type qrVersion struct {
+	Total      int // codewords, data and error correction
+	ECPerBlock int
+	Blocks     int
+}
+
+type qrMatrix struct {
+	Modules  []bool // dark modules, row by row
+	Function []bool // finder, timing, alignment and format modules, masking leaves them alone
+	Size     int
+}
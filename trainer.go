@@ -0,0 +1,249 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	tea "github.com/charmbracelet/bubbletea"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ------------------- Counting Systems -----------------------
+
+// countSystemsWithCustom appends the tag tables of countFile, if there is one, to the built-in systems
+func countSystemsWithCustom() []countSystem {
+	systems := make([]countSystem, 0, len(builtinCountSystems)+1)
+	systems = append(systems, builtinCountSystems...)
+	custom, err := loadCustomCountSystems(store.path(countFile))
+	if err != nil {
+		log.Println(err)
+		return systems
+	}
+	return append(systems, custom...)
+}
+
+// Format: [{"name": "My Count", "tags": [A, 2, 3, 4, 5, 6, 7, 8, 9, 10], "balanced": true}]
+func loadCustomCountSystems(filename string) ([]countSystem, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open %s: %w", filename, err)
+	}
+	defer func() {
+		closeErr := file.Close()
+		if closeErr != nil {
+			log.Println("Error while closing " + filename + ": " + closeErr.Error())
+		}
+	}()
+
+	var systems []countSystem
+	err = json.NewDecoder(file).Decode(&systems)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", filename, err)
+	}
+	for _, system := range systems {
+		if system.Name == emptyString {
+			return nil, fmt.Errorf("every counting system in %s needs a name", filename)
+		}
+		if strings.ContainsAny(system.Name, "\r\n") {
+			return nil, fmt.Errorf("the name %q in %s has a line break", system.Name, filename)
+		}
+	}
+	return systems, nil
+}
+
+func (cs countSystem) tag(c card) int {
+	return cs.Tags[cardIndex(c)]
+}
+
+func (cs countSystem) runningCount(cards []card) int {
+	count := 0
+	for _, c := range cards {
+		count += cs.tag(c)
+	}
+	return count
+}
+
+func decksRemaining(gs gameState) float64 {
+	return float64(int(gs.NumberDecks)*52-int(gs.CardsDealt)) / 52
+}
+
+func trueCount(runningCount int, gs gameState) float64 {
+	decks := decksRemaining(gs)
+	if decks <= 0 {
+		return 0
+	}
+	return float64(runningCount) / decks
+}
+
+// dealtCards rebuilds the cards dealt since the last shuffle from RandomSeed and CardsDealt,
+// the DrawStack only holds the cards that are still to come.
+func dealtCards(gs gameState) []card {
+	shoe, _ := newDeck(gs.NumberDecks, make([]card, 0, int(gs.NumberDecks)*52), gs.RandomSeed)
+	return shoe[:min(int(gs.CardsDealt), len(shoe))]
+}
+
+// ------------------- Count Trainer --------------------------
+
+func cycleCountSystem(m blackjackModel) blackjackModel {
+	if m.Trainer.System == 0 {
+		m.Trainer.Systems = countSystemsWithCustom()
+	}
+	m.Trainer.System = (m.Trainer.System + 1) % (len(m.Trainer.Systems) + 1)
+	m.Trainer.Rounds = 0
+	m.Trainer.CheckpointSeed = m.Game.RandomSeed
+	m.Trainer.Checkpoint = m.Game.CardsDealt
+	return m
+}
+
+func (t trainerState) active() bool {
+	return t.System > 0
+}
+
+func (t trainerState) system() countSystem {
+	return t.Systems[t.System-1]
+}
+
+func trainerLabel(m blackjackModel) string {
+	if !m.Trainer.active() {
+		return emptyString
+	}
+	return m.UiText.TrainerLabel + m.Trainer.system().Name + " " +
+		strconv.Itoa(m.Trainer.Correct) + "/" + strconv.Itoa(m.Trainer.Asked)
+}
+
+// quizDue is checked on "Restart / Bet", before a reshuffle would reset the count
+func quizDue(m blackjackModel) bool {
+	return m.Trainer.active() && m.Trainer.Rounds+1 >= trainerInterval
+}
+
+func startQuiz(m blackjackModel) blackjackModel {
+	m.Trainer.Rounds = 0
+	m.Trainer.Input = emptyString
+	m.Trainer.Answered = false
+	m.Trainer.AskTrueCount = m.Trainer.system().Balanced && m.Trainer.Asked%2 == 1
+	m.Game.Phase = phaseQuiz
+	return m
+}
+
+func handleQuizKey(m blackjackModel, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.Trainer.Answered {
+		if msg.Type == tea.KeyEnter {
+			return finishQuiz(m), nil
+		}
+		return m, nil
+	}
+
+	switch msg.Type {
+	case tea.KeyRunes:
+		for _, r := range msg.Runes {
+			if (r >= '0' && r <= '9') || r == '-' || r == '.' {
+				m.Trainer.Input += string(r)
+			}
+		}
+	case tea.KeyBackspace:
+		if m.Trainer.Input != emptyString {
+			m.Trainer.Input = m.Trainer.Input[:len(m.Trainer.Input)-1]
+		}
+	case tea.KeyEnter:
+		return answerQuiz(m), nil
+	}
+	return m, nil
+}
+
+// answerQuiz accepts running counts exactly and true counts within half a point
+func answerQuiz(m blackjackModel) blackjackModel {
+	answer, err := strconv.ParseFloat(m.Trainer.Input, 64)
+	if err != nil {
+		return m
+	}
+
+	system := m.Trainer.system()
+	dealt := dealtCards(m.Game)
+	running := system.runningCount(dealt)
+	exactTrue := trueCount(running, m.Game)
+	var correct bool
+	if m.Trainer.AskTrueCount {
+		correct = math.Abs(answer-exactTrue) <= 0.5
+	} else {
+		correct = answer == float64(running)
+	}
+
+	start := 0
+	if m.Trainer.CheckpointSeed == m.Game.RandomSeed && int(m.Trainer.Checkpoint) <= len(dealt) {
+		start = int(m.Trainer.Checkpoint)
+	}
+	m.Trainer.Missed = dealt[start:]
+	m.Trainer.Checkpoint = m.Game.CardsDealt
+	m.Trainer.CheckpointSeed = m.Game.RandomSeed
+
+	m.Trainer.Asked++
+	m.Trainer.Answered = true
+	m.Trainer.WasCorrect = correct
+	if correct {
+		m.Trainer.Correct++
+	}
+	m.Trainer.Result = m.UiText.CountRunning + strconv.Itoa(running) + ", " +
+		m.UiText.CountTrue + strconv.FormatFloat(exactTrue, 'f', 1, 64)
+	return m
+}
+
+// finishQuiz continues with what "Restart / Bet" would have done
+func finishQuiz(m blackjackModel) blackjackModel {
+	m.Game = reshuffleIfNeeded(m.Game)
+	if m.Game.CardsDealt == 0 {
+		m.Trainer.Checkpoint = 0
+		m.Trainer.CheckpointSeed = m.Game.RandomSeed
+	}
+	m.Game.Phase = phaseBet
+	m.UiState.Cursor = 0
+	return m
+}
+
+func renderQuiz(b []byte, m blackjackModel) string {
+	system := m.Trainer.system()
+	question := m.UiText.CountQuestionRunning
+	if m.Trainer.AskTrueCount {
+		question = m.UiText.CountQuestionTrue
+	}
+	b = m.wrapAndPad(b, trainerLabel(m))
+	b = append(b, newlineRune)
+	b = append(b, newlineRune)
+	b = m.wrapAndPad(b, question)
+	b = append(b, newlineRune)
+	b = m.wrapAndPad(b, optionCursorPrefix+m.Trainer.Input)
+	b = append(b, newlineRune)
+	b = append(b, newlineRune)
+
+	if m.Trainer.Answered {
+		if m.Trainer.WasCorrect {
+			b = m.wrapAndPad(b, m.UiText.CountCorrect+m.Trainer.Result)
+			b = append(b, newlineRune)
+		} else {
+			b = m.wrapAndPad(b, m.UiText.CountWrong+m.Trainer.Result)
+			b = append(b, newlineRune)
+			var missed strings.Builder
+			for _, c := range m.Trainer.Missed {
+				missed.WriteString(bjCards.CardCodes[rankToIndex(c.Rank)][suitToIndex(c.Suit)])
+				missed.WriteString(fmt.Sprintf("%+d ", system.tag(c)))
+			}
+			b = m.wrapAndPad(b, m.UiText.CountMissed)
+			b = append(b, newlineRune)
+			b = m.wrapAndPad(b, missed.String())
+			b = append(b, newlineRune)
+		}
+		b = append(b, newlineRune)
+		b = m.wrapAndPad(b, m.UiText.CountContinue)
+	} else {
+		b = m.wrapAndPad(b, m.UiText.CountInputPrompt)
+	}
+	b = append(b, newlineRune)
+	b = append(b, newlineRune)
+	b = m.verticalPad(b)
+	return string(b)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestCustomCountSystemNames(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		ok   bool
+	}{
+		{"a name with a bar", `[{"name": "Hi|Lo", "tags": [-1, 1, 1, 1, 1, 1, 0, 0, 0, -1], "balanced": true}]`, true},
+		{"no name", `[{"tags": [-1, 1, 1, 1, 1, 1, 0, 0, 0, -1], "balanced": true}]`, false},
+		{"a name over two lines", `[{"name": "Hi\nLo", "tags": [-1, 1, 1, 1, 1, 1, 0, 0, 0, -1], "balanced": true}]`, false},
+	}
+	for _, test := range tests {
+		filename := filepath.Join(t.TempDir(), countFile)
+		err := os.WriteFile(filename, []byte(test.json), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		systems, err := loadCustomCountSystems(filename)
+		if (err == nil) != test.ok {
+			t.Errorf("%s: got %v", test.name, err)
+		}
+		if test.ok && (len(systems) != 1 || systems[0].Name != "Hi|Lo") {
+			t.Errorf("%s: got %+v", test.name, systems)
+		}
+	}
+}
+
+func TestBalancedSystemsCountFullShoeToZero(t *testing.T) {
+	shoe, _ := newDeck(2, make([]card, 0, 104), 3)
+	for _, system := range builtinCountSystems {
+		got := system.runningCount(shoe)
+		want := 0
+		if !system.Balanced {
+			want = 2 * 4 // KO counts the four sevens of each deck
+		}
+		if got != want {
+			t.Errorf("%s counts a shoe of two decks to %d, want %d", system.Name, got, want)
+		}
+	}
+}
+
+func TestDealtCardsMatchTheHand(t *testing.T) {
+	gs := midHandGame(t)
+	dealt := dealtCards(gs)
+	if len(dealt) != int(gs.CardsDealt) {
+		t.Fatalf("rebuilt %d cards, %d were dealt", len(dealt), gs.CardsDealt)
+	}
+	if got := builtinCountSystems[0].runningCount(dealt); got != int(gs.HiLoCount) {
+		t.Errorf("the rebuilt cards count to %d, the game counted %d", got, gs.HiLoCount)
+	}
+	if got := trueCount(6, gameState{NumberDecks: 6, CardsDealt: 104}); got != 1.5 {
+		t.Errorf("6 with four decks left is a true count of %v", got)
+	}
+}
+
+func TestAnswerQuiz(t *testing.T) {
+	m := tableModel(t, midHandGame(t))
+	m.Trainer = trainerState{Systems: builtinCountSystems, System: 1}
+	running := builtinCountSystems[0].runningCount(dealtCards(m.Game))
+	exactTrue := trueCount(running, m.Game)
+
+	tests := []struct {
+		name      string
+		trueCount bool
+		input     string
+		correct   bool
+	}{
+		{"running count", false, strconv.Itoa(running), true},
+		{"running count off by one", false, strconv.Itoa(running + 1), false},
+		{"true count within half a point", true, strconv.FormatFloat(exactTrue+0.4, 'f', 2, 64), true},
+		{"true count off by more", true, strconv.FormatFloat(exactTrue-0.6, 'f', 2, 64), false},
+	}
+	for _, test := range tests {
+		quiz := m
+		quiz.Trainer.AskTrueCount = test.trueCount
+		quiz.Trainer.Input = test.input
+		quiz = answerQuiz(quiz)
+		if !quiz.Trainer.Answered || quiz.Trainer.WasCorrect != test.correct || quiz.Trainer.Asked != 1 {
+			t.Errorf("%s: answered %v, correct %v", test.name, quiz.Trainer.Answered, quiz.Trainer.WasCorrect)
+		}
+		if len(quiz.Trainer.Missed) != int(m.Game.CardsDealt) {
+			t.Errorf("%s: %d missed cards shown, %d were dealt", test.name, len(quiz.Trainer.Missed), m.Game.CardsDealt)
+		}
+	}
+
+	m.Trainer.Input = "-"
+	if answerQuiz(m).Trainer.Answered {
+		t.Error("an answer that is no number is scored")
+	}
+}
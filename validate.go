@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+)
+
+// ------------------- Save Validation ------------------------
+
+// validateSavable rejects a save no game of this version can reach or go on with, like no decks,
+// a payout the rules engine doesn't know, more cards dealt than the shoe holds or a broke player
+// between rounds. What the game can go on with safely is repaired and listed instead.
+func validateSavable(savableState savableGameState) (savableGameState, []string, error) {
+	s := savableState
+	totalCards := uint16(s.NumberDecks) * 52
+	switch {
+	case s.NumberDecks < 1 || s.NumberDecks > maxDecks:
+		return s, nil, fmt.Errorf("invalid number of decks: %d, the game deals 1 to %d", s.NumberDecks, maxDecks)
+	case s.Payout != 15 && s.Payout != 14 && s.Payout != 12:
+		return s, nil, fmt.Errorf("invalid payout: %d, the game pays 3:2 (15), 7:5 (14) or 6:5 (12)", s.Payout)
+	case s.CardsDealt > totalCards:
+		return s, nil, fmt.Errorf("invalid cards dealt: %d of %d decks", s.CardsDealt, s.NumberDecks)
+	case s.PlayerCardCount != 0 && (s.PlayerCardCount < 2 || s.PlayerCardCount > 22 ||
+		int(s.CardsDealt) < int(s.PlayerCardCount)+2 || s.Turn != turnPlayer):
+		return s, nil, fmt.Errorf("invalid hand in progress: %d player cards of %d dealt, turn %d",
+			s.PlayerCardCount, s.CardsDealt, s.Turn)
+	case s.PlayerCardCount != 0 && (s.Bet < smallestBet || s.Bet > 5*smallestBet || s.Bet%smallestBet != 0):
+		return s, nil, fmt.Errorf("invalid bet of the hand in progress: %d, bets are %d to %d in steps of %d",
+			s.Bet, smallestBet, 5*smallestBet, smallestBet)
+	case s.PlayerCardCount == 0 && s.Bet != 0:
+		return s, nil, fmt.Errorf("invalid bet: %d without a hand in progress", s.Bet)
+	case s.PlayerCardCount == 0 && s.playerMoney < smallestBet:
+		return s, nil, fmt.Errorf("invalid money: %d is less than the smallest bet of %d, the game was over", s.playerMoney, smallestBet)
+	}
+
+	var repairs []string
+	deepest := uint8(75) // the penetrations handleConfigStepPen offers
+	if s.NumberDecks < 2 {
+		deepest = 50
+	}
+	if limit := reshuffleThreshold(s.NumberDecks, deepest); s.ReshuffleThreshold > limit {
+		repairs = append(repairs, fmt.Sprintf("reshuffle threshold %d lowered to %d, the deepest penetration of %d decks",
+			s.ReshuffleThreshold, limit, s.NumberDecks))
+		s.ReshuffleThreshold = limit
+	}
+	// every deal sets the flag once the threshold is reached, without it the shoe could run out
+	if s.CardsDealt > 0 && s.CardsDealt >= s.ReshuffleThreshold && !s.NeedReshuffle {
+		repairs = append(repairs, fmt.Sprintf("reshuffle set, %d cards are dealt of a threshold of %d",
+			s.CardsDealt, s.ReshuffleThreshold))
+		s.NeedReshuffle = true
+	}
+	// a hand is only dealt before the reshuffle point, so the shoe still holds the cards it needs
+	if s.PlayerCardCount != 0 {
+		before := s.CardsDealt - uint16(s.PlayerCardCount) - 2
+		if before > 0 && before >= s.ReshuffleThreshold {
+			return s, nil, fmt.Errorf("invalid hand in progress: dealt after %d cards, the reshuffle point is %d",
+				before, s.ReshuffleThreshold)
+		}
+	}
+	return s, repairs, nil
+}
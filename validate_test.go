@@ -0,0 +1,143 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// savableTable is a fresh table between rounds, the state every save of these rules starts from
+func savableTable(decks uint8, pen uint8) savableGameState {
+	return savableGameState{
+		RandomSeed:         1,
+		ReshuffleThreshold: reshuffleThreshold(decks, pen),
+		NumberDecks:        decks,
+		playerMoney:        startingMoney,
+		Payout:             15,
+	}
+}
+
+func TestSimulatorAndSavesShareDeckLimit(t *testing.T) {
+	for _, decks := range []int{0, 1, 6, 8, 17, maxDecks} {
+		_, simErr := newSimConfig(1, payout32, false, decks, 50, "basic", 10, 1, 1)
+		_, _, saveErr := validateSavable(savableTable(uint8(decks), 50))
+		if (simErr == nil) != (saveErr == nil) {
+			t.Errorf("%d decks: the simulator says %v, a save says %v", decks, simErr, saveErr)
+		}
+	}
+}
+
+func TestValidateSavableRejects(t *testing.T) {
+	withHand := func(s savableGameState, bet uint16, cards uint8) savableGameState {
+		s.CardsDealt = 10
+		s.Bet = bet
+		s.PlayerCardCount = cards
+		s.Turn = turnPlayer
+		return s
+	}
+	tests := []struct {
+		name string
+		edit func(s savableGameState) savableGameState
+	}{
+		{"no decks", func(s savableGameState) savableGameState { s.NumberDecks = 0; return s }},
+		{"unknown payout", func(s savableGameState) savableGameState { s.Payout = 13; return s }},
+		{"more cards dealt than the shoe", func(s savableGameState) savableGameState { s.CardsDealt = 2*52 + 1; return s }},
+		{"one player card", func(s savableGameState) savableGameState { return withHand(s, 10, 1) }},
+		{"hand of more cards than dealt", func(s savableGameState) savableGameState { return withHand(s, 10, 9) }},
+		{"hand on the turn of the dealer", func(s savableGameState) savableGameState { s = withHand(s, 10, 2); s.Turn = turnDealer; return s }},
+		{"bet below the table", func(s savableGameState) savableGameState { return withHand(s, 5, 2) }},
+		{"bet above the table", func(s savableGameState) savableGameState { return withHand(s, 60, 2) }},
+		{"bet between the steps", func(s savableGameState) savableGameState { return withHand(s, 25, 2) }},
+		{"bet without a hand", func(s savableGameState) savableGameState { s.Bet = 10; return s }},
+		{"broke between rounds", func(s savableGameState) savableGameState { s.playerMoney = smallestBet - 1; return s }},
+		{"hand dealt past the reshuffle point", func(s savableGameState) savableGameState {
+			s = withHand(s, 10, 2)
+			s.CardsDealt = s.ReshuffleThreshold + 4
+			return s
+		}},
+	}
+	for _, test := range tests {
+		_, _, err := validateSavable(test.edit(savableTable(2, 50)))
+		if err == nil {
+			t.Errorf("%s: the save passes", test.name)
+		}
+	}
+
+	_, repairs, err := validateSavable(withHand(savableTable(2, 50), 50, 3))
+	if err != nil || len(repairs) != 0 {
+		t.Errorf("a valid hand in progress: %v, repairs %v", err, repairs)
+	}
+}
+
+func TestValidateSavableRepairs(t *testing.T) {
+	deep := savableTable(1, 50)
+	deep.ReshuffleThreshold = 52 // 100 % of one deck, the screen offers 50 % at most
+	got, repairs, err := validateSavable(deep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ReshuffleThreshold != reshuffleThreshold(1, 50) || len(repairs) != 1 {
+		t.Errorf("threshold %d, repairs %v; want %d and one repair", got.ReshuffleThreshold, repairs, reshuffleThreshold(1, 50))
+	}
+
+	due := savableTable(6, 75)
+	due.CardsDealt = due.ReshuffleThreshold + 3
+	got, repairs, err = validateSavable(due)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.NeedReshuffle || len(repairs) != 1 {
+		t.Errorf("reshuffle %v, repairs %v; want the reshuffle set and one repair", got.NeedReshuffle, repairs)
+	}
+
+	both := savableTable(2, 75)
+	both.ReshuffleThreshold = 104
+	both.CardsDealt = 90
+	got, repairs, err = validateSavable(both)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ReshuffleThreshold != reshuffleThreshold(2, 75) || !got.NeedReshuffle || len(repairs) != 2 {
+		t.Errorf("threshold %d, reshuffle %v, repairs %v; want both repaired", got.ReshuffleThreshold, got.NeedReshuffle, repairs)
+	}
+
+	for _, fine := range []savableGameState{savableTable(1, 0), savableTable(1, 50), savableTable(6, 75), savableTable(maxDecks, 75)} {
+		_, repairs, err = validateSavable(fine)
+		if err != nil || len(repairs) != 0 {
+			t.Errorf("a table of the config screen: %v, repairs %v", err, repairs)
+		}
+	}
+}
+
+func TestRepairedSaveLoads(t *testing.T) {
+	gs := gameState{RandomSeed: 11, NumberDecks: 1, Payout: 15, PlayerMoney: 200, ReshuffleThreshold: 52, CardsDealt: 30}
+	code, err := gs.encodeWithChecksum()
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, repairs, err := decodeGameState(inspectNoTimestamp + singleSpaceString + code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.ReshuffleThreshold != 26 || !loaded.NeedReshuffle || len(repairs) != 2 {
+		t.Errorf("threshold %d, reshuffle %v, repairs %v", loaded.ReshuffleThreshold, loaded.NeedReshuffle, repairs)
+	}
+}
+
+func TestFullyDealtShoeDealsAfterLoad(t *testing.T) {
+	resetStorage(t)
+	gs := gameState{RandomSeed: 11, NumberDecks: 1, Payout: 15, PlayerMoney: 100, ReshuffleThreshold: 26, CardsDealt: 52}
+	err := storeSlot("Player", gs, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := loadSlot(t, "Player")
+	if m.Game.Phase != phaseBet || m.Game.CardsDealt != 0 {
+		t.Fatalf("phase %v with %d cards dealt; want a fresh shoe before the bet", m.Game.Phase, m.Game.CardsDealt)
+	}
+	model, _ := handleBetSelection(m, bet10)
+	m = model.(blackjackModel)
+	if m.Game.CardsDealt < 4 || m.Game.Bet != 10 {
+		t.Errorf("%d cards dealt for a bet of %d; want a round dealt for 10", m.Game.CardsDealt, m.Game.Bet)
+	}
+}
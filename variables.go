@@ -1,8 +1,10 @@
 package main
 
 import (
+	"errors"
 	"regexp"
 	"sync"
+	"time"
 )
 
 var (
@@ -144,6 +146,97 @@ func suitToIndex(suit string) int8 {
 	}
 }
 
+// ------------------- Simulator ------------------------------
+
+var strategies = map[string]strategy{
+	"basic":      basicStrategy,
+	"mimic":      mimicDealerStrategy,
+	"never-bust": neverBustStrategy,
+
+	"composition":       compositionStrategy,
+	"index":             indexStrategy,
+	"composition-index": compositionIndexStrategy,
+}
+
+// Hi-Lo indices for multi-deck S17 games, Fab 4 first so its surrenders win
+var indexPlays = []indexPlay{
+	{Set: "Fab 4", Total: 14, Upcard: 9, Index: 3, AtOrAbove: actionSurrender},
+	{Set: "Fab 4", Total: 15, Upcard: 9, Index: 0, AtOrAbove: actionSurrender, Below: actionHit},
+	{Set: "Fab 4", Total: 15, Upcard: 8, Index: 2, AtOrAbove: actionSurrender},
+	{Set: "Fab 4", Total: 15, Upcard: 0, Index: 1, AtOrAbove: actionSurrender},
+	{Set: "Illustrious 18", Total: 16, Upcard: 9, Index: 0, AtOrAbove: actionStand},
+	{Set: "Illustrious 18", Total: 15, Upcard: 9, Index: 4, AtOrAbove: actionStand},
+	{Set: "Illustrious 18", Total: 10, Upcard: 9, Index: 4, AtOrAbove: actionDouble},
+	{Set: "Illustrious 18", Total: 12, Upcard: 2, Index: 2, AtOrAbove: actionStand, Below: actionHit},
+	{Set: "Illustrious 18", Total: 12, Upcard: 1, Index: 3, AtOrAbove: actionStand, Below: actionHit},
+	{Set: "Illustrious 18", Total: 11, Upcard: 0, Index: 1, AtOrAbove: actionDouble},
+	{Set: "Illustrious 18", Total: 9, Upcard: 1, Index: 1, AtOrAbove: actionDouble, Below: actionHit},
+	{Set: "Illustrious 18", Total: 10, Upcard: 0, Index: 4, AtOrAbove: actionDouble},
+	{Set: "Illustrious 18", Total: 9, Upcard: 6, Index: 3, AtOrAbove: actionDouble, Below: actionHit},
+	{Set: "Illustrious 18", Total: 16, Upcard: 8, Index: 5, AtOrAbove: actionStand},
+	{Set: "Illustrious 18", Total: 13, Upcard: 1, Index: -1, AtOrAbove: actionStand, Below: actionHit},
+	{Set: "Illustrious 18", Total: 12, Upcard: 3, Index: 0, AtOrAbove: actionStand, Below: actionHit},
+	{Set: "Illustrious 18", Total: 12, Upcard: 4, Index: -2, AtOrAbove: actionStand, Below: actionHit},
+	{Set: "Illustrious 18", Total: 12, Upcard: 5, Index: -1, AtOrAbove: actionStand, Below: actionHit},
+	{Set: "Illustrious 18", Total: 13, Upcard: 2, Index: -2, AtOrAbove: actionStand, Below: actionHit},
+}
+
+var (
+	strategyTables   = make(map[strategyKey]*strategyTable)
+	strategyTablesMu sync.Mutex
+
+	compositionCache   = make(map[compositionKey]actionEVs)
+	compositionCacheMu sync.Mutex
+)
+
+var betPolicies = map[string]betPolicy{
+	"flat":       flatPolicy,
+	"ramp":       rampPolicy,
+	"kelly":      kellyPolicy,
+	"martingale": martingalePolicy,
+	"paroli":     paroliPolicy,
+}
+
+var errInvalidJSON = errors.New("invalid JSON")
+
+// reasons a save is rejected, see loadErrorReason
+var (
+	errSaveTampered     = errors.New("MAC mismatch")
+	errSaveUnsigned     = errors.New("save without MAC")
+	errSaveUnverifiable = errors.New("MAC can't be verified")
+	errSaveChecksum     = errors.New("checksum mismatch")
+)
+
+// action names of the bot protocol, indexed by the action constants
+var actionNames = [5]string{"", "hit", "stand", "double", "surrender"}
+
+var outcomeNames = [9]string{
+	noOutcome:           "none",
+	naturalBlackjackWin: "natural blackjack win",
+	normalWin:           "normal win",
+	normalLoose:         "normal loss",
+	normalDraw:          "normal draw",
+	doubleWin:           "double win",
+	doubleLoose:         "double loss",
+	doubleDraw:          "double draw",
+	surrender:           "surrender",
+}
+
+// ------------------- Count Trainer --------------------------
+
+// tags by cardIndex: A, 2, 3, 4, 5, 6, 7, 8, 9, 10
+// paces of the counting drill, from beginner to casino speed
+var drillPaces = []time.Duration{1000 * time.Millisecond, 700 * time.Millisecond, 500 * time.Millisecond, 400 * time.Millisecond, 300 * time.Millisecond, 200 * time.Millisecond}
+
+var drillCardCounts = []int{13, 26, 39, 51, 52}
+
+var builtinCountSystems = []countSystem{
+	{Name: "Hi-Lo", Tags: [10]int{-1, 1, 1, 1, 1, 1, 0, 0, 0, -1}, Balanced: true},
+	{Name: "KO", Tags: [10]int{-1, 1, 1, 1, 1, 1, 1, 0, 0, -1}, Balanced: false},
+	{Name: "Omega II", Tags: [10]int{0, 1, 1, 2, 2, 2, 1, 0, -1, -2}, Balanced: true},
+	{Name: "Zen", Tags: [10]int{-1, 1, 1, 2, 2, 2, 1, 0, 0, -2}, Balanced: true},
+}
+
 // ------------------- Regex ----------------------------------
 
 var regexIntegers = regexp.MustCompile(`\d+`)
@@ -168,3 +261,45 @@ var base45Lookup = [256]int8{
 	-1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1,
 	-1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1,
 }
+
+// envActions maps the discrete actions of blackjackEnv to the action constants
+var envActions = [4]int{actionHit, actionStand, actionDouble, actionSurrender}
+
+// levels of the bankroll sparkline, lowest first
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// ------------------- Save Versions --------------------------
+
+// byte length of every save layout, indexed by the version
+var saveLayoutLengths = [saveVersion + 1]int{13, 14, 18}
+
+// saveMigrations[v] turns a layout of version v into version v+1
+var saveMigrations = [saveVersion]func([]byte) []byte{migrateSaveV0, migrateSaveV1}
+
+// ------------------- Storage --------------------------------
+
+// store is resolved once in run, before any model reads or writes a file
+var store storage
+
+// files of older versions that adoptLegacyFiles copies into the data directory. debugFile is
+// left out, setupLogging starts a new log in the data directory on every run anyway.
+var legacyFiles = []string{saveFile, statsFile, drillFile, countFile}
+
+// saveMode is saveModeCasual or saveModeMAC, set by the -save-mode flag
+var saveMode = saveModeCasual
+
+// installSecret loads secret.key once, the first time a save is signed or verified
+var installSecret = sync.OnceValues(func() ([]byte, error) {
+	return loadInstallSecret(store.path(secretFile))
+})
+
+// qrVersions are the QR versions 1 to 6 at error correction level M, a signed save code
+// needs version 3. Each version splits its codewords into blocks of equal length.
+var qrVersions = [...]qrVersion{
+	{Total: 26, ECPerBlock: 10, Blocks: 1},
+	{Total: 44, ECPerBlock: 16, Blocks: 1},
+	{Total: 70, ECPerBlock: 26, Blocks: 1},
+	{Total: 100, ECPerBlock: 18, Blocks: 2},
+	{Total: 134, ECPerBlock: 24, Blocks: 2},
+	{Total: 172, ECPerBlock: 16, Blocks: 4},
+}